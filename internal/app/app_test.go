@@ -316,6 +316,55 @@ func TestSendRequest_FollowRedirects(t *testing.T) {
 	}
 }
 
+func TestSendRequest_Timings(t *testing.T) {
+	app := newTestApp()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	resp := app.SendRequest(HTTPRequest{Method: "GET", URL: server.URL})
+
+	if resp.Error != "" {
+		t.Fatalf("Unexpected error: %s", resp.Error)
+	}
+	if resp.Timings == nil {
+		t.Fatal("Timings should be populated")
+	}
+	if resp.Timings.WroteRequestMs < 0 || resp.Timings.FirstByteAtMs < 0 {
+		t.Error("Timings should not contain negative offsets")
+	}
+}
+
+func TestSendRequest_HopTimingsOnRedirect(t *testing.T) {
+	app := newTestApp()
+
+	finalServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer finalServer.Close()
+
+	redirectServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, finalServer.URL, http.StatusFound)
+	}))
+	defer redirectServer.Close()
+
+	resp := app.SendRequest(HTTPRequest{
+		Method:          "GET",
+		URL:             redirectServer.URL,
+		FollowRedirects: true,
+	})
+
+	if len(resp.Hops) != 1 {
+		t.Fatalf("Expected 1 hop, got %d", len(resp.Hops))
+	}
+	if resp.Hops[0].StatusCode != http.StatusFound {
+		t.Errorf("Hop StatusCode = %d, want %d", resp.Hops[0].StatusCode, http.StatusFound)
+	}
+}
+
 func TestCreateCustomHTTPClient(t *testing.T) {
 	app := newTestApp()
 
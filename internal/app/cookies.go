@@ -0,0 +1,154 @@
+package app
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+
+	"golang.org/x/net/publicsuffix"
+
+	"volt-api/internal/database"
+)
+
+// getCookieJar returns the cookie jar for an environment, lazily creating it
+// and seeding it from the database the first time it's needed.
+func (a *App) getCookieJar(environmentID string) (*cookiejar.Jar, error) {
+	a.cookieMu.Lock()
+	defer a.cookieMu.Unlock()
+
+	if jar, ok := a.cookieJars[environmentID]; ok {
+		return jar, nil
+	}
+
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, err
+	}
+
+	if a.db != nil {
+		if cookies, err := a.db.GetCookies(environmentID); err == nil {
+			seedJar(jar, cookies)
+		}
+	}
+
+	a.cookieJars[environmentID] = jar
+	return jar, nil
+}
+
+// seedJar groups stored cookies by domain and replays them into a fresh jar
+// via SetCookies, since cookiejar.Jar exposes no direct insertion API.
+func seedJar(jar *cookiejar.Jar, cookies []database.Cookie) {
+	byDomain := make(map[string][]*http.Cookie)
+	for _, c := range cookies {
+		byDomain[c.Domain] = append(byDomain[c.Domain], &http.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			Secure:   c.Secure,
+			HttpOnly: c.HTTPOnly,
+		})
+	}
+	for domain, cookies := range byDomain {
+		jar.SetCookies(&url.URL{Scheme: "https", Host: domain, Path: "/"}, cookies)
+	}
+}
+
+// persistCookies saves newly received Set-Cookie values to the database so
+// they survive a restart. The cached jar is left as is: the client already
+// stored the same cookies in it while handling the response. requestURL is the URL the cookies came back from; it's only consulted as a
+// fallback for host-only cookies (see below).
+func (a *App) persistCookies(environmentID, requestURL string, cookies []*http.Cookie) {
+	var requestHost string
+	if parsed, err := url.Parse(requestURL); err == nil {
+		requestHost = parsed.Hostname()
+	}
+
+	for _, c := range cookies {
+		path := c.Path
+		if path == "" {
+			path = "/"
+		}
+		var expires int64
+		if !c.Expires.IsZero() {
+			expires = c.Expires.Unix()
+		}
+		domain := c.Domain
+		if domain == "" {
+			// A Set-Cookie with no Domain attribute is host-only: the
+			// standard library leaves http.Cookie.Domain empty and scopes it
+			// to the response's own host instead. seedJar groups stored
+			// cookies by Domain and replays them against that host, so an
+			// empty Domain here would group under "" and never match any
+			// host on restart — fall back to the host it actually came from.
+			domain = requestHost
+		}
+		a.db.UpsertCookie(environmentID, database.Cookie{
+			Domain:   domain,
+			Path:     path,
+			Name:     c.Name,
+			Value:    c.Value,
+			Expires:  expires,
+			Secure:   c.Secure,
+			HTTPOnly: c.HttpOnly,
+		})
+	}
+}
+
+// ListCookies returns all cookies stored for an environment
+func (a *App) ListCookies(envID string) []Cookie {
+	if a.db == nil {
+		return []Cookie{}
+	}
+
+	items, err := a.db.GetCookies(envID)
+	if err != nil {
+		return []Cookie{}
+	}
+
+	result := make([]Cookie, len(items))
+	for i, c := range items {
+		result[i] = Cookie{
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Name:     c.Name,
+			Value:    c.Value,
+			Expires:  c.Expires,
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+		}
+	}
+	return result
+}
+
+// DeleteCookie removes a single cookie and invalidates the cached jar
+func (a *App) DeleteCookie(envID, domain, name string) error {
+	if a.db == nil {
+		return nil
+	}
+	if err := a.db.DeleteCookie(envID, domain, name); err != nil {
+		return err
+	}
+	a.invalidateCookieJar(envID)
+	return nil
+}
+
+// ClearCookies removes all cookies for an environment and invalidates its jar
+func (a *App) ClearCookies(envID string) error {
+	if a.db == nil {
+		return nil
+	}
+	if err := a.db.ClearCookies(envID); err != nil {
+		return err
+	}
+	a.invalidateCookieJar(envID)
+	return nil
+}
+
+// invalidateCookieJar drops the cached jar so it's rebuilt from the database
+// (which reflects any deletions) on next use.
+func (a *App) invalidateCookieJar(environmentID string) {
+	a.cookieMu.Lock()
+	delete(a.cookieJars, environmentID)
+	a.cookieMu.Unlock()
+}
@@ -0,0 +1,347 @@
+package app
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ImportCurl parses a POSIX-shell-quoted curl invocation into an HTTPRequest.
+func (a *App) ImportCurl(cmd string) (HTTPRequest, error) {
+	tokens, err := tokenizeCurl(cmd)
+	if err != nil {
+		return HTTPRequest{}, err
+	}
+	if len(tokens) == 0 {
+		return HTTPRequest{}, fmt.Errorf("empty curl command")
+	}
+	if tokens[0] == "curl" {
+		tokens = tokens[1:]
+	}
+
+	request := HTTPRequest{
+		Method:          "GET",
+		Headers:         make(map[string]string),
+		FollowRedirects: false,
+		MaxRedirects:    10,
+	}
+	var dataParts []string
+	var formParts []string
+	var sawDataFlag bool
+
+	next := func(i int) (string, int, error) {
+		if i+1 >= len(tokens) {
+			return "", i, fmt.Errorf("flag %s is missing a value", tokens[i])
+		}
+		return tokens[i+1], i + 1, nil
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok == "-X" || tok == "--request":
+			var val string
+			var err error
+			val, i, err = next(i)
+			if err != nil {
+				return HTTPRequest{}, err
+			}
+			request.Method = strings.ToUpper(val)
+
+		case tok == "-H" || tok == "--header":
+			var val string
+			var err error
+			val, i, err = next(i)
+			if err != nil {
+				return HTTPRequest{}, err
+			}
+			if key, value, ok := strings.Cut(val, ":"); ok {
+				request.Headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+
+		case tok == "-d" || tok == "--data" || tok == "--data-raw" || tok == "--data-binary" || tok == "--data-ascii":
+			var val string
+			var err error
+			val, i, err = next(i)
+			if err != nil {
+				return HTTPRequest{}, err
+			}
+			sawDataFlag = true
+			dataParts = append(dataParts, val)
+
+		case tok == "--data-urlencode":
+			var val string
+			var err error
+			val, i, err = next(i)
+			if err != nil {
+				return HTTPRequest{}, err
+			}
+			sawDataFlag = true
+			dataParts = append(dataParts, urlEncodeCurlDataArg(val))
+
+		case tok == "-F" || tok == "--form":
+			var val string
+			var err error
+			val, i, err = next(i)
+			if err != nil {
+				return HTTPRequest{}, err
+			}
+			formParts = append(formParts, val)
+
+		case tok == "-u" || tok == "--user":
+			var val string
+			var err error
+			val, i, err = next(i)
+			if err != nil {
+				return HTTPRequest{}, err
+			}
+			request.Headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(val))
+
+		case tok == "--url":
+			var val string
+			var err error
+			val, i, err = next(i)
+			if err != nil {
+				return HTTPRequest{}, err
+			}
+			request.URL = val
+
+		case tok == "-k" || tok == "--insecure":
+			request.SkipSSLVerify = true
+
+		case tok == "-x" || tok == "--proxy":
+			var val string
+			var err error
+			val, i, err = next(i)
+			if err != nil {
+				return HTTPRequest{}, err
+			}
+			request.ProxyURL = val
+
+		case tok == "-L" || tok == "--location":
+			request.FollowRedirects = true
+
+		case tok == "--max-redirs":
+			var val string
+			var err error
+			val, i, err = next(i)
+			if err != nil {
+				return HTTPRequest{}, err
+			}
+			if n, err := strconv.Atoi(val); err == nil {
+				request.MaxRedirects = n
+			}
+
+		case tok == "-m" || tok == "--max-time":
+			var val string
+			var err error
+			val, i, err = next(i)
+			if err != nil {
+				return HTTPRequest{}, err
+			}
+			if n, err := strconv.Atoi(val); err == nil {
+				request.Timeout = n
+			}
+
+		case tok == "-E" || tok == "--cert":
+			var val string
+			var err error
+			val, i, err = next(i)
+			if err != nil {
+				return HTTPRequest{}, err
+			}
+			request.ClientCertPath = val
+
+		case tok == "--key":
+			var val string
+			var err error
+			val, i, err = next(i)
+			if err != nil {
+				return HTTPRequest{}, err
+			}
+			request.ClientKeyPath = val
+
+		case strings.HasPrefix(tok, "-"):
+			// Unrecognized flag: skip the flag token only. A value it takes
+			// is not consumed and is read as the next token (as the URL, if
+			// none has been seen yet).
+
+		default:
+			if request.URL == "" {
+				request.URL = tok
+			}
+		}
+	}
+
+	if len(formParts) > 0 {
+		var b strings.Builder
+		for i, part := range formParts {
+			if i > 0 {
+				b.WriteString("&")
+			}
+			b.WriteString(part)
+		}
+		request.Body = b.String()
+		if request.Headers["Content-Type"] == "" {
+			request.Headers["Content-Type"] = "multipart/form-data"
+		}
+		if request.Method == "GET" {
+			request.Method = "POST"
+		}
+	} else if len(dataParts) > 0 {
+		request.Body = strings.Join(dataParts, "&")
+		if request.Headers["Content-Type"] == "" {
+			request.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+		}
+		if request.Method == "GET" && sawDataFlag {
+			request.Method = "POST"
+		}
+	}
+
+	if request.URL == "" {
+		return HTTPRequest{}, fmt.Errorf("no URL found in curl command")
+	}
+
+	return request, nil
+}
+
+// urlEncodeCurlDataArg handles curl's `--data-urlencode name=value` /
+// `--data-urlencode =value` forms by percent-encoding only the value half.
+func urlEncodeCurlDataArg(arg string) string {
+	if key, value, ok := strings.Cut(arg, "="); ok {
+		return key + "=" + curlPercentEncode(value)
+	}
+	return curlPercentEncode(arg)
+}
+
+func curlPercentEncode(s string) string {
+	var b strings.Builder
+	for _, r := range []byte(s) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == '~':
+			b.WriteByte(r)
+		default:
+			fmt.Fprintf(&b, "%%%02X", r)
+		}
+	}
+	return b.String()
+}
+
+// tokenizeCurl joins backslash-newline continuations and splits the command
+// using POSIX shell quoting rules (single quotes, double quotes, escapes).
+func tokenizeCurl(cmd string) ([]string, error) {
+	joined := strings.ReplaceAll(cmd, "\\\n", " ")
+	joined = strings.ReplaceAll(joined, "\\\r\n", " ")
+
+	var tokens []string
+	var cur strings.Builder
+	hasToken := false
+	inSingle, inDouble := false, false
+
+	flush := func() {
+		if hasToken {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+			hasToken = false
+		}
+	}
+
+	runes := []rune(joined)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case inSingle:
+			if c == '\'' {
+				inSingle = false
+			} else {
+				cur.WriteRune(c)
+			}
+		case inDouble:
+			if c == '"' {
+				inDouble = false
+			} else if c == '\\' && i+1 < len(runes) && strings.ContainsRune(`"\$`+"`", runes[i+1]) {
+				i++
+				cur.WriteRune(runes[i])
+			} else {
+				cur.WriteRune(c)
+			}
+		case c == '\'':
+			inSingle = true
+			hasToken = true
+		case c == '"':
+			inDouble = true
+			hasToken = true
+		case c == '\\' && i+1 < len(runes):
+			i++
+			cur.WriteRune(runes[i])
+			hasToken = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			flush()
+		default:
+			cur.WriteRune(c)
+			hasToken = true
+		}
+	}
+	if inSingle || inDouble {
+		return nil, fmt.Errorf("unterminated quote in curl command")
+	}
+	flush()
+
+	return tokens, nil
+}
+
+// ExportCurl renders an HTTPRequest back into a multi-line, shell-escaped
+// curl command suitable for pasting into a terminal.
+func (a *App) ExportCurl(request HTTPRequest) (string, error) {
+	if request.URL == "" {
+		return "", fmt.Errorf("URL is required")
+	}
+
+	var b strings.Builder
+	b.WriteString("curl")
+
+	method := strings.ToUpper(request.Method)
+	if method != "" && method != "GET" {
+		fmt.Fprintf(&b, " \\\n  -X %s", shellQuote(method))
+	}
+
+	for key, value := range request.Headers {
+		fmt.Fprintf(&b, " \\\n  -H %s", shellQuote(key+": "+value))
+	}
+
+	if request.SkipSSLVerify {
+		b.WriteString(" \\\n  -k")
+	}
+	if request.ProxyURL != "" {
+		fmt.Fprintf(&b, " \\\n  -x %s", shellQuote(request.ProxyURL))
+	}
+	if request.FollowRedirects {
+		b.WriteString(" \\\n  -L")
+		if request.MaxRedirects > 0 {
+			fmt.Fprintf(&b, " --max-redirs %d", request.MaxRedirects)
+		}
+	}
+	if request.Timeout > 0 {
+		fmt.Fprintf(&b, " \\\n  -m %d", request.Timeout)
+	}
+	if request.ClientCertPath != "" {
+		fmt.Fprintf(&b, " \\\n  -E %s", shellQuote(request.ClientCertPath))
+	}
+	if request.ClientKeyPath != "" {
+		fmt.Fprintf(&b, " \\\n  --key %s", shellQuote(request.ClientKeyPath))
+	}
+	if request.Body != "" {
+		fmt.Fprintf(&b, " \\\n  -d %s", shellQuote(request.Body))
+	}
+
+	fmt.Fprintf(&b, " \\\n  %s", shellQuote(request.URL))
+
+	return b.String(), nil
+}
+
+// shellQuote wraps a value in single quotes, escaping embedded single quotes
+// as '\'' per POSIX shell convention.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
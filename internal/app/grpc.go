@@ -0,0 +1,515 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// grpcFiles caches reflected/loaded descriptor files per target so repeated
+// discovery and invocation calls don't re-fetch them over the wire.
+type grpcFiles struct {
+	mu    sync.Mutex
+	files map[string]*protoregistry.Files // keyed by target
+}
+
+var grpcDescriptorCache = &grpcFiles{files: make(map[string]*protoregistry.Files)}
+
+// useTLSFor reports whether target should be dialed over TLS. :443 is the
+// conventional secure-gRPC port, so a bare host:port naming it is assumed
+// to want TLS; skipSSLVerify additionally forces TLS on, since a caller
+// asking to skip certificate verification is asking for an encrypted
+// connection whose cert just isn't checked, not a plaintext one. This only
+// decides plain-vs-TLS — whether the handshake verifies the peer cert is
+// controlled solely by skipSSLVerify, passed through as InsecureSkipVerify.
+func useTLSFor(target string, skipSSLVerify bool) bool {
+	return strings.HasSuffix(target, ":443") || skipSSLVerify
+}
+
+// dialGRPC opens a plain or TLS client connection to target, skipping
+// verification when requested, mirroring createCustomHTTPClient's knobs.
+func dialGRPC(target string, skipSSLVerify bool) (*grpc.ClientConn, error) {
+	var creds credentials.TransportCredentials
+	if useTLSFor(target, skipSSLVerify) {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: skipSSLVerify})
+	} else {
+		creds = insecure.NewCredentials()
+	}
+	return grpc.Dial(target, grpc.WithTransportCredentials(creds))
+}
+
+// reflectFiles discovers every service exposed via grpc.reflection.v1alpha and
+// returns a populated descriptor registry for that target.
+func reflectFiles(ctx context.Context, conn *grpc.ClientConn) (*protoregistry.Files, []string, error) {
+	client := grpc_reflection_v1alpha.NewServerReflectionClient(conn)
+	stream, err := client.ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open reflection stream: %w", err)
+	}
+	defer stream.CloseSend()
+
+	if err := stream.Send(&grpc_reflection_v1alpha.ServerReflectionRequest{
+		MessageRequest: &grpc_reflection_v1alpha.ServerReflectionRequest_ListServices{ListServices: "*"},
+	}); err != nil {
+		return nil, nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	listResp, err := stream.Recv()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to receive service list: %w", err)
+	}
+
+	var serviceNames []string
+	for _, s := range listResp.GetListServicesResponse().GetService() {
+		serviceNames = append(serviceNames, s.GetName())
+	}
+
+	seen := make(map[string][]byte)
+	for _, name := range serviceNames {
+		if err := stream.Send(&grpc_reflection_v1alpha.ServerReflectionRequest{
+			MessageRequest: &grpc_reflection_v1alpha.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: name},
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to request descriptor for %s: %w", name, err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to receive descriptor for %s: %w", name, err)
+		}
+		for _, raw := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fdProto := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(raw, fdProto); err != nil {
+				continue
+			}
+			seen[fdProto.GetName()] = raw
+		}
+	}
+
+	files := new(protoregistry.Files)
+	if err := buildFileRegistry(files, seen); err != nil {
+		return nil, nil, err
+	}
+	return files, serviceNames, nil
+}
+
+// buildFileRegistry decodes raw FileDescriptorProtos and registers them in
+// dependency order so protodesc.NewFile can resolve imports.
+func buildFileRegistry(files *protoregistry.Files, raw map[string][]byte) error {
+	pending := make(map[string]*descriptorpb.FileDescriptorProto, len(raw))
+	for name, b := range raw {
+		fdProto := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(b, fdProto); err != nil {
+			return fmt.Errorf("failed to unmarshal descriptor %s: %w", name, err)
+		}
+		pending[name] = fdProto
+	}
+
+	var register func(name string, visiting map[string]bool) error
+	register = func(name string, visiting map[string]bool) error {
+		if _, err := files.FindFileByPath(name); err == nil {
+			return nil
+		}
+		fdProto, ok := pending[name]
+		if !ok {
+			return nil // dependency not reflected (e.g. well-known types); protodesc falls back to globals
+		}
+		if visiting[name] {
+			return fmt.Errorf("circular descriptor dependency at %s", name)
+		}
+		visiting[name] = true
+		for _, dep := range fdProto.GetDependency() {
+			if err := register(dep, visiting); err != nil {
+				return err
+			}
+		}
+		fd, err := protodesc.NewFile(fdProto, files)
+		if err != nil {
+			return fmt.Errorf("failed to build descriptor for %s: %w", name, err)
+		}
+		return files.RegisterFile(fd)
+	}
+
+	for name := range pending {
+		if err := register(name, make(map[string]bool)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DiscoverGRPCServices enumerates the services and methods exposed by a
+// reflection-enabled gRPC server so the UI can populate a method picker.
+func (a *App) DiscoverGRPCServices(target string, skipSSLVerify bool) ([]GRPCServiceDescriptor, error) {
+	conn, err := dialGRPC(target, skipSSLVerify)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", target, err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(a.ctx, 10*time.Second)
+	defer cancel()
+
+	files, serviceNames, err := reflectFiles(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	grpcDescriptorCache.mu.Lock()
+	grpcDescriptorCache.files[target] = files
+	grpcDescriptorCache.mu.Unlock()
+
+	var out []GRPCServiceDescriptor
+	for _, name := range serviceNames {
+		if name == "grpc.reflection.v1alpha.ServerReflection" {
+			continue
+		}
+		desc, err := files.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			continue
+		}
+		sd, ok := desc.(protoreflect.ServiceDescriptor)
+		if !ok {
+			continue
+		}
+		svc := GRPCServiceDescriptor{Name: name}
+		methods := sd.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			m := methods.Get(i)
+			svc.Methods = append(svc.Methods, GRPCMethodDescriptor{
+				Name:            string(m.Name()),
+				InputType:       string(m.Input().FullName()),
+				OutputType:      string(m.Output().FullName()),
+				ServerStreaming: m.IsStreamingServer(),
+				ClientStreaming: m.IsStreamingClient(),
+			})
+		}
+		out = append(out, svc)
+	}
+	return out, nil
+}
+
+// resolveMethod looks up the method descriptor for a request, reflecting
+// against the target if the descriptor set isn't already cached.
+func (a *App) resolveMethod(ctx context.Context, conn *grpc.ClientConn, request GRPCRequest) (protoreflect.MethodDescriptor, error) {
+	grpcDescriptorCache.mu.Lock()
+	files := grpcDescriptorCache.files[request.Target]
+	grpcDescriptorCache.mu.Unlock()
+
+	if files == nil {
+		var err error
+		files, _, err = reflectFiles(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+		grpcDescriptorCache.mu.Lock()
+		grpcDescriptorCache.files[request.Target] = files
+		grpcDescriptorCache.mu.Unlock()
+	}
+
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(request.Service))
+	if err != nil {
+		return nil, fmt.Errorf("unknown service %s: %w", request.Service, err)
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", request.Service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(request.Method))
+	if md == nil {
+		return nil, fmt.Errorf("unknown method %s on %s", request.Method, request.Service)
+	}
+	return md, nil
+}
+
+// SendGRPCRequest invokes a unary or server-streaming gRPC method, transcoding
+// the JSON payload to protobuf and the reply back to JSON.
+func (a *App) SendGRPCRequest(request GRPCRequest) GRPCResponse {
+	startTime := time.Now()
+
+	if request.Target == "" || request.Service == "" || request.Method == "" {
+		return GRPCResponse{Error: "target, service, and method are required"}
+	}
+
+	timeout := DefaultTimeout
+	if request.Timeout > 0 {
+		timeout = time.Duration(request.Timeout) * time.Second
+		if timeout > MaxTimeout {
+			timeout = MaxTimeout
+		}
+	}
+
+	if request.UseWeb {
+		return a.sendGRPCWebRequest(request, timeout, startTime)
+	}
+
+	conn, err := dialGRPC(request.Target, request.SkipSSLVerify)
+	if err != nil {
+		return GRPCResponse{Error: fmt.Sprintf("failed to dial %s: %v", request.Target, err)}
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(a.ctx, timeout)
+	defer cancel()
+
+	md, err := a.resolveMethod(ctx, conn, request)
+	if err != nil {
+		return GRPCResponse{Error: err.Error()}
+	}
+
+	inMsg := dynamicpb.NewMessage(md.Input())
+	if request.Payload != "" {
+		if err := protojson.Unmarshal([]byte(request.Payload), inMsg); err != nil {
+			return GRPCResponse{Error: fmt.Sprintf("failed to encode payload: %v", err)}
+		}
+	}
+
+	outCtx := ctx
+	if len(request.Headers) > 0 {
+		outCtx = metadata.NewOutgoingContext(ctx, metadata.New(request.Headers))
+	}
+
+	fullMethod := fmt.Sprintf("/%s/%s", request.Service, request.Method)
+	var trailer metadata.MD
+
+	if md.IsStreamingServer() {
+		stream, err := conn.NewStream(outCtx, &grpc.StreamDesc{ServerStreams: true}, fullMethod, grpc.Trailer(&trailer))
+		if err != nil {
+			return a.grpcErrorResponse(err, startTime)
+		}
+		if err := stream.SendMsg(inMsg); err != nil {
+			return a.grpcErrorResponse(err, startTime)
+		}
+		if err := stream.CloseSend(); err != nil {
+			return a.grpcErrorResponse(err, startTime)
+		}
+
+		var messages []string
+		for {
+			outMsg := dynamicpb.NewMessage(md.Output())
+			if err := stream.RecvMsg(outMsg); err == io.EOF {
+				break
+			} else if err != nil {
+				resp := a.grpcErrorResponse(err, startTime)
+				resp.Messages = messages
+				return resp
+			}
+			jsonBytes, err := protojson.Marshal(outMsg)
+			if err != nil {
+				continue
+			}
+			messages = append(messages, string(jsonBytes))
+		}
+
+		return GRPCResponse{
+			Messages:   messages,
+			Trailers:   mdToMap(trailer),
+			StatusCode: 0,
+			StatusName: "OK",
+			TimingMs:   time.Since(startTime).Milliseconds(),
+		}
+	}
+
+	outMsg := dynamicpb.NewMessage(md.Output())
+	err = conn.Invoke(outCtx, fullMethod, inMsg, outMsg, grpc.Trailer(&trailer))
+	if err != nil {
+		return a.grpcErrorResponse(err, startTime)
+	}
+
+	jsonBytes, err := protojson.Marshal(outMsg)
+	if err != nil {
+		return GRPCResponse{Error: fmt.Sprintf("failed to decode reply: %v", err)}
+	}
+
+	return GRPCResponse{
+		Message:    string(jsonBytes),
+		Trailers:   mdToMap(trailer),
+		StatusCode: 0,
+		StatusName: "OK",
+		TimingMs:   time.Since(startTime).Milliseconds(),
+	}
+}
+
+// grpcErrorResponse converts a gRPC error into a GRPCResponse carrying the
+// status code, name, and any trailing metadata surfaced alongside it.
+func (a *App) grpcErrorResponse(err error, startTime time.Time) GRPCResponse {
+	st := status.Convert(err)
+	return GRPCResponse{
+		Error:      st.Message(),
+		StatusCode: uint32(st.Code()),
+		StatusName: st.Code().String(),
+		TimingMs:   time.Since(startTime).Milliseconds(),
+	}
+}
+
+// mdToMap flattens gRPC metadata (multi-valued per key) into the
+// comma-joined string map the frontend already expects for HTTP headers.
+func mdToMap(md metadata.MD) map[string]string {
+	out := make(map[string]string, len(md))
+	for k, v := range md {
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
+
+// sendGRPCWebRequest performs a unary gRPC-Web call over plain HTTP/1.1,
+// framing the request/response bodies per the grpc-web wire protocol
+// (1-byte compression flag + 4-byte big-endian length prefix per message,
+// with a trailer frame flagged 0x80).
+func (a *App) sendGRPCWebRequest(request GRPCRequest, timeout time.Duration, startTime time.Time) GRPCResponse {
+	conn, err := dialGRPC(request.Target, request.SkipSSLVerify)
+	if err != nil {
+		return GRPCResponse{Error: fmt.Sprintf("failed to dial %s: %v", request.Target, err)}
+	}
+	ctx, cancel := context.WithTimeout(a.ctx, timeout)
+	defer cancel()
+	md, err := a.resolveMethod(ctx, conn, request)
+	conn.Close()
+	if err != nil {
+		return GRPCResponse{Error: err.Error()}
+	}
+
+	inMsg := dynamicpb.NewMessage(md.Input())
+	if request.Payload != "" {
+		if err := protojson.Unmarshal([]byte(request.Payload), inMsg); err != nil {
+			return GRPCResponse{Error: fmt.Sprintf("failed to encode payload: %v", err)}
+		}
+	}
+	body, err := proto.Marshal(inMsg)
+	if err != nil {
+		return GRPCResponse{Error: fmt.Sprintf("failed to marshal request: %v", err)}
+	}
+
+	var buf bytes.Buffer
+	writeGRPCWebFrame(&buf, 0, body)
+
+	scheme := "http"
+	if useTLSFor(request.Target, request.SkipSSLVerify) {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s/%s/%s", scheme, request.Target, request.Service, request.Method)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &buf)
+	if err != nil {
+		return GRPCResponse{Error: fmt.Sprintf("failed to build request: %v", err)}
+	}
+	httpReq.Header.Set("Content-Type", "application/grpc-web+proto")
+	httpReq.Header.Set("X-Grpc-Web", "1")
+	for k, v := range request.Headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: request.SkipSSLVerify},
+		},
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return GRPCResponse{Error: fmt.Sprintf("request failed: %v", err), TimingMs: time.Since(startTime).Milliseconds()}
+	}
+	defer resp.Body.Close()
+
+	rawBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return GRPCResponse{Error: fmt.Sprintf("failed to read response: %v", err), TimingMs: time.Since(startTime).Milliseconds()}
+	}
+	if resp.Header.Get("Content-Type") == "application/grpc-web-text+proto" {
+		decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(rawBody)))
+		if err == nil {
+			rawBody = decoded
+		}
+	}
+
+	var message string
+	trailers := make(map[string]string)
+	r := bytes.NewReader(rawBody)
+	for {
+		flag, payload, err := readGRPCWebFrame(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return GRPCResponse{Error: fmt.Sprintf("malformed grpc-web frame: %v", err), TimingMs: time.Since(startTime).Milliseconds()}
+		}
+		if flag&0x80 != 0 {
+			for _, line := range strings.Split(string(payload), "\r\n") {
+				if kv := strings.SplitN(line, ":", 2); len(kv) == 2 {
+					trailers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+				}
+			}
+			continue
+		}
+		outMsg := dynamicpb.NewMessage(md.Output())
+		if err := proto.Unmarshal(payload, outMsg); err != nil {
+			return GRPCResponse{Error: fmt.Sprintf("failed to decode reply: %v", err), TimingMs: time.Since(startTime).Milliseconds()}
+		}
+		jsonBytes, err := protojson.Marshal(outMsg)
+		if err != nil {
+			continue
+		}
+		message = string(jsonBytes)
+	}
+
+	statusCode := codes.OK
+	if s := trailers["grpc-status"]; s != "" {
+		if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+			statusCode = codes.Code(n)
+		}
+	}
+
+	return GRPCResponse{
+		Message:    message,
+		Trailers:   trailers,
+		StatusCode: uint32(statusCode),
+		StatusName: statusCode.String(),
+		Error:      trailers["grpc-message"],
+		TimingMs:   time.Since(startTime).Milliseconds(),
+	}
+}
+
+// writeGRPCWebFrame writes a single length-prefixed grpc-web frame.
+func writeGRPCWebFrame(w io.Writer, flag byte, payload []byte) {
+	header := make([]byte, 5)
+	header[0] = flag
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+	w.Write(header)
+	w.Write(payload)
+}
+
+// readGRPCWebFrame reads a single length-prefixed grpc-web frame, returning
+// io.EOF once the stream is exhausted.
+func readGRPCWebFrame(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[1:])
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[0], payload, nil
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"sort"
+
+	"volt-api/internal/environment"
+)
+
+// interpolateRequest expands "{{var}}" references in request's URL, header
+// values, and body against the active and global environments, returning a
+// copy of request with those fields resolved. Unresolved references are
+// left untouched in the output and named in unresolved (sorted,
+// de-duplicated across all three fields) rather than failing the request,
+// so a typo in a variable name doesn't block sending — the caller surfaces
+// unresolved through HTTPResponse.UnresolvedVariables instead. err is only
+// set for a reference cycle or for nesting deeper than the resolver's depth
+// limit, neither of which has a sensible literal output.
+func (a *App) interpolateRequest(request HTTPRequest) (resolved HTTPRequest, unresolved []string, err error) {
+	var envVars, globalVars map[string]string
+	if a.db != nil {
+		envVars, _ = a.db.GetActiveEnvironmentVariablesDecrypted()
+		globalVars, _ = a.db.GetGlobalEnvironmentVariablesDecrypted()
+	}
+
+	r := environment.NewResolver(nil, nil, envVars, globalVars)
+
+	unresolvedSet := make(map[string]bool)
+	merge := func(names []string) {
+		for _, n := range names {
+			unresolvedSet[n] = true
+		}
+	}
+
+	resolved = request
+
+	resolved.URL, unresolved, err = r.ResolveLenient(request.URL)
+	if err != nil {
+		return HTTPRequest{}, nil, err
+	}
+	merge(unresolved)
+
+	if len(request.Headers) > 0 {
+		resolvedHeaders := make(map[string]string, len(request.Headers))
+		for key, value := range request.Headers {
+			resolvedValue, names, err := r.ResolveLenient(value)
+			if err != nil {
+				return HTTPRequest{}, nil, err
+			}
+			resolvedHeaders[key] = resolvedValue
+			merge(names)
+		}
+		resolved.Headers = resolvedHeaders
+	}
+
+	resolved.Body, unresolved, err = r.ResolveLenient(request.Body)
+	if err != nil {
+		return HTTPRequest{}, nil, err
+	}
+	merge(unresolved)
+
+	unresolved = make([]string, 0, len(unresolvedSet))
+	for name := range unresolvedSet {
+		unresolved = append(unresolved, name)
+	}
+	sort.Strings(unresolved)
+
+	return resolved, unresolved, nil
+}
+
+// PreviewInterpolatedRequest resolves request's "{{var}}" references the
+// same way SendRequest does, without sending it, so the frontend can show
+// what a saved request will actually look like against the active
+// environment before running it.
+func (a *App) PreviewInterpolatedRequest(request HTTPRequest) InterpolatedRequestPreview {
+	resolved, unresolved, err := a.interpolateRequest(request)
+	if err != nil {
+		return InterpolatedRequestPreview{Error: err.Error()}
+	}
+
+	return InterpolatedRequestPreview{
+		URL:                 resolved.URL,
+		Headers:             resolved.Headers,
+		Body:                resolved.Body,
+		UnresolvedVariables: unresolved,
+	}
+}
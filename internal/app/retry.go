@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryBackoffMs    = 500
+	defaultRetryMaxBackoffMs = 30000
+)
+
+// shouldRetryRequest decides whether an attempt's outcome warrants another
+// try: a matching response status, or a transient network/timeout error.
+func shouldRetryRequest(request HTTPRequest, resp HTTPResponse, sendErr error) bool {
+	if len(request.RetryOnStatuses) > 0 && resp.StatusCode != 0 {
+		for _, code := range request.RetryOnStatuses {
+			if code == resp.StatusCode {
+				return true
+			}
+		}
+	}
+	return isTransientError(sendErr)
+}
+
+// isTransientError reports whether err looks like a retryable network
+// failure: a context deadline, a net.Error flagged as a timeout, or a
+// connection reset/refused by the peer.
+func isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true // covers connection reset, refused, etc.
+	}
+	return false
+}
+
+// computeRetryBackoff returns how long to wait before the next attempt,
+// honoring a server-supplied Retry-After if present, otherwise using
+// min(RetryBackoffMs * 2^attempt, RetryMaxBackoffMs) with optional full jitter.
+func computeRetryBackoff(request HTTPRequest, attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	base := request.RetryBackoffMs
+	if base <= 0 {
+		base = defaultRetryBackoffMs
+	}
+	maxBackoff := request.RetryMaxBackoffMs
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRetryMaxBackoffMs
+	}
+
+	backoff := base << uint(attempt) // base * 2^attempt
+	if backoff <= 0 || backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	if request.RetryJitter && backoff > 0 {
+		backoff = rand.Intn(backoff + 1) // full jitter: uniform in [0, backoff]
+	}
+
+	return time.Duration(backoff) * time.Millisecond
+}
+
+// parseRetryAfter reads a Retry-After header in either its seconds or
+// HTTP-date form, returning 0 if absent or unparsable.
+func parseRetryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
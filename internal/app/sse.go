@@ -0,0 +1,177 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// SSEEvent is emitted over the Wails runtime for every event dispatched by
+// a stream opened with OpenSSEStream.
+type SSEEvent struct {
+	StreamID string `json:"streamId"`
+	Id       string `json:"id"`
+	Event    string `json:"event"`
+	Data     string `json:"data"`
+}
+
+// SSEErrorEvent is emitted once, in place of SSECloseEvent, when a stream
+// ends because of a transport error rather than the server closing it.
+type SSEErrorEvent struct {
+	StreamID string `json:"streamId"`
+	Error    string `json:"error"`
+}
+
+// SSECloseEvent is emitted once a stream ends, whether the server closed
+// it or CloseStream was called locally.
+type SSECloseEvent struct {
+	StreamID string `json:"streamId"`
+}
+
+// OpenSSEStream issues request and, if the response is a
+// "text/event-stream", parses it as a stream of server-sent events,
+// emitting each as "sse:message" until the stream ends or CloseStream(id)
+// is called. The returned ID is registered in the same a.pending map
+// SendRequestAsync uses, so CancelRequest also works against it.
+func (a *App) OpenSSEStream(request HTTPRequest) (string, error) {
+	method, bodyBytes, _, errResp := a.validateRequest(request)
+	if errResp != nil {
+		return "", fmt.Errorf("%s", errResp.Error)
+	}
+
+	// SSE connections are long-lived by design, so unlike SendRequest's
+	// bounded retry-then-buffer timeout, the client has no overall
+	// deadline; CancelRequest/CloseStream is how a caller ends it.
+	httpClient := a.createCustomHTTPClient(request, 0)
+
+	ctx, cancel := context.WithCancel(a.ctx)
+
+	var bodyReader io.Reader
+	if len(bodyBytes) > 0 {
+		bodyReader = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, request.URL, bodyReader)
+	if err != nil {
+		cancel()
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range request.Headers {
+		if strings.EqualFold(key, "host") {
+			continue
+		}
+		req.Header.Set(key, value)
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		cancel()
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(strings.ToLower(contentType), "text/event-stream") {
+		resp.Body.Close()
+		cancel()
+		return "", fmt.Errorf("response is not an event stream (Content-Type: %q)", contentType)
+	}
+
+	streamID := uuid.New().String()
+	a.pendingMu.Lock()
+	a.pending[streamID] = &pendingRequest{cancel: cancel}
+	a.pendingMu.Unlock()
+
+	go a.readSSEStream(streamID, resp)
+
+	return streamID, nil
+}
+
+// readSSEStream parses resp.Body as an SSE framing and emits each dispatched
+// event as "sse:message" until the body closes, then emits either
+// "sse:close" (server closed the stream, or CloseStream tore it down) or
+// "sse:error" (a transport error while reading). retry: lines are ignored;
+// reconnecting is left to the caller re-invoking OpenSSEStream.
+func (a *App) readSSEStream(streamID string, resp *http.Response) {
+	defer resp.Body.Close()
+	defer a.finishPending(streamID)
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxResponseBodySize)
+
+	var (
+		dataLines   []string
+		eventName   string
+		lastEventID string
+	)
+
+	dispatch := func() {
+		if len(dataLines) == 0 {
+			eventName = ""
+			return
+		}
+		if eventName == "" {
+			eventName = "message"
+		}
+		runtime.EventsEmit(a.ctx, "sse:message", SSEEvent{
+			StreamID: streamID,
+			Id:       lastEventID,
+			Event:    eventName,
+			Data:     strings.Join(dataLines, "\n"),
+		})
+		dataLines = nil
+		eventName = ""
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			dispatch()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment line
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			eventName = value
+		case "data":
+			dataLines = append(dataLines, value)
+		case "id":
+			lastEventID = value
+		case "retry":
+			// Reconnection delay hints are the caller's concern, not ours:
+			// OpenSSEStream doesn't auto-reconnect, so there's nothing to
+			// apply this to.
+		}
+	}
+
+	// A read that fails because CloseStream/CancelRequest cancelled the
+	// request's context is a local teardown, reported as sse:close.
+	if err := scanner.Err(); err != nil && resp.Request.Context().Err() == nil {
+		runtime.EventsEmit(a.ctx, "sse:error", SSEErrorEvent{StreamID: streamID, Error: err.Error()})
+		return
+	}
+	runtime.EventsEmit(a.ctx, "sse:close", SSECloseEvent{StreamID: streamID})
+}
+
+// CloseStream ends the SSE stream or async request identified by id. It's
+// a thin, more discoverable alias for CancelRequest sharing the exact same
+// underlying mechanism.
+func (a *App) CloseStream(id string) bool {
+	return a.CancelRequest(id)
+}
@@ -0,0 +1,360 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"volt-api/internal/database"
+	"volt-api/internal/storage"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// syncKeyUnsafe matches characters that aren't safe to use in a storage
+// object key; slugKey mirrors ascode's filename slug so remote keys read
+// the same way the on-disk as-code export names do, except that a name with
+// no safe characters becomes "untitled" here and "environment" in ascode.
+var syncKeyUnsafe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+func slugKey(name string) string {
+	slug := syncKeyUnsafe.ReplaceAllString(strings.ToLower(name), "-")
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		slug = "untitled"
+	}
+	return slug
+}
+
+// historyPushLimit bounds how many of the most recent history items SyncNow
+// considers pushing in one pass; history only grows, so a generous
+// recent-first window keeps each sync pass cheap without needing to track
+// a high-water mark the way a replicator does for changelog rows.
+const historyPushLimit = 500
+
+// ConfigureRemoteStorage points future SyncNow calls at a remote object
+// store of the given kind ("local", "s3", or "swift") built from config.
+// Passing kind == "" clears the configured backend and disables sync.
+func (a *App) ConfigureRemoteStorage(kind string, config map[string]string) error {
+	a.storageMu.Lock()
+	defer a.storageMu.Unlock()
+
+	if kind == "" {
+		a.storageBackend = nil
+		return nil
+	}
+
+	backend, err := storage.NewBackend(kind, config)
+	if err != nil {
+		return err
+	}
+
+	a.storageBackend = backend
+	return nil
+}
+
+// SyncNow pushes and pulls collections, environments, and history against
+// the configured remote storage backend, emitting "sync:progress" events
+// (the same way SendRequestAsync emits "response:progress") so the UI can
+// show a spinner. Environments get a full two-way per-variable merge;
+// collections are push-only, with remote-only collections pulled in once;
+// history is append-only, namespaced by device ID so multiple installs can
+// share a bucket without colliding.
+func (a *App) SyncNow() error {
+	a.storageMu.Lock()
+	backend := a.storageBackend
+	a.storageMu.Unlock()
+
+	if backend == nil {
+		return fmt.Errorf("remote storage not configured")
+	}
+	if a.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	a.emitSyncProgress("environments")
+	if err := a.syncEnvironments(backend); err != nil {
+		return fmt.Errorf("environment sync failed: %w", err)
+	}
+
+	a.emitSyncProgress("collections")
+	if err := a.syncCollections(backend); err != nil {
+		return fmt.Errorf("collection sync failed: %w", err)
+	}
+
+	a.emitSyncProgress("history")
+	if err := a.syncHistory(backend); err != nil {
+		return fmt.Errorf("history sync failed: %w", err)
+	}
+
+	a.emitSyncProgress("done")
+	return nil
+}
+
+func (a *App) emitSyncProgress(stage string) {
+	runtime.EventsEmit(a.ctx, "sync:progress", map[string]interface{}{"stage": stage})
+}
+
+// syncEnvironments pushes every local environment to
+// "environments/<slug>.json" and pulls every remote one back, merging by
+// variable key with last-write-wins on UpdatedAt. Secret variables are
+// never merged in either direction: ExportEnvironment already redacts them
+// to secretPlaceholder, and applying a placeholder over a real secret
+// would destroy it.
+func (a *App) syncEnvironments(backend storage.Backend) error {
+	envs, err := a.db.GetEnvironments()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(envs))
+	for _, env := range envs {
+		key := "environments/" + slugKey(env.Name) + ".json"
+		seen[key] = true
+
+		local, err := a.db.ExportEnvironment(env.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := a.pushMergedEnvironment(backend, key, env.ID, local); err != nil {
+			return err
+		}
+	}
+
+	remote, err := backend.List("environments/")
+	if err != nil {
+		return err
+	}
+	for _, obj := range remote {
+		if seen[obj.Key] {
+			continue
+		}
+
+		full, err := backend.Get(obj.Key)
+		if err != nil {
+			return err
+		}
+
+		var export database.EnvironmentExport
+		if err := json.Unmarshal(full.Data, &export); err != nil {
+			continue
+		}
+		if _, err := a.db.ImportEnvironment(&export); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pushMergedEnvironment merges local against whatever is currently stored
+// at key (if anything), applies the merged non-secret variables locally,
+// and writes the merged result back with an If-Match conditional write. On
+// a conflicting concurrent write it re-fetches and retries once, the same
+// re-fetch-and-retry shape PutObject's IfMatch is meant to drive.
+func (a *App) pushMergedEnvironment(backend storage.Backend, key, envID string, local *database.EnvironmentExport) error {
+	ifMatch := ""
+
+	for attempt := 0; attempt < 2; attempt++ {
+		remoteObj, err := backend.Get(key)
+		merged := local
+		switch {
+		case err == storage.ErrNotFound:
+			ifMatch = ""
+		case err != nil:
+			return err
+		default:
+			ifMatch = remoteObj.ETag
+
+			var remoteExport database.EnvironmentExport
+			if err := json.Unmarshal(remoteObj.Data, &remoteExport); err != nil {
+				return err
+			}
+			merged = mergeEnvironmentExports(local, &remoteExport)
+
+			if err := a.applyEnvironmentMerge(envID, merged); err != nil {
+				return err
+			}
+		}
+
+		data, err := json.MarshalIndent(merged, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = backend.Put(key, data, ifMatch)
+		if err == storage.ErrPreconditionFailed {
+			continue // someone else wrote in between; re-fetch and re-merge
+		}
+		return err
+	}
+
+	return fmt.Errorf("environment sync: too many conflicting writes to %s", key)
+}
+
+// mergeEnvironmentExports combines local and remote variable lists by key,
+// keeping whichever side's variable has the newer UpdatedAt, and skipping
+// secret variables entirely (they travel as secretPlaceholder and would
+// otherwise clobber real values on either side).
+func mergeEnvironmentExports(local, remote *database.EnvironmentExport) *database.EnvironmentExport {
+	byKey := make(map[string]database.EnvironmentVariable, len(local.Variables)+len(remote.Variables))
+	order := make([]string, 0, len(local.Variables)+len(remote.Variables))
+
+	for _, v := range local.Variables {
+		if v.IsSecret() {
+			continue
+		}
+		byKey[v.Key] = v
+		order = append(order, v.Key)
+	}
+
+	for _, v := range remote.Variables {
+		if v.IsSecret() {
+			continue
+		}
+		existing, ok := byKey[v.Key]
+		if !ok {
+			order = append(order, v.Key)
+			byKey[v.Key] = v
+			continue
+		}
+		if v.UpdatedAt > existing.UpdatedAt {
+			byKey[v.Key] = v
+		}
+	}
+
+	merged := &database.EnvironmentExport{Name: local.Name}
+	for _, key := range order {
+		merged.Variables = append(merged.Variables, byKey[key])
+	}
+
+	// Secrets never travel through sync; keep the local copies as-is so a
+	// rollback-free local secret isn't silently dropped from the merge.
+	for _, v := range local.Variables {
+		if v.IsSecret() {
+			merged.Variables = append(merged.Variables, v)
+		}
+	}
+
+	return merged
+}
+
+// applyEnvironmentMerge writes merged's non-secret variables into envID via
+// SetEnvironmentVariable, which already upserts by key — so re-applying a
+// variable that's unchanged locally is a harmless no-op write.
+func (a *App) applyEnvironmentMerge(envID string, merged *database.EnvironmentExport) error {
+	for _, v := range merged.Variables {
+		if v.IsSecret() {
+			continue
+		}
+		if _, err := a.db.SetEnvironmentVariable(envID, v.Key, v.Value, v.Type, v.Enabled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncCollections pushes every local collection to
+// "collections/<slug>.json" and pulls in any remote collection that has no
+// local counterpart by name. Updating a collection that's already been
+// synced from a subsequent remote edit is not implemented yet — tracked as
+// follow-up work, the same way replicator.pull documents deferring replay
+// of changelog rows into the live tables.
+func (a *App) syncCollections(backend storage.Backend) error {
+	collections, err := a.db.GetCollections()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(collections))
+	for _, c := range collections {
+		key := "collections/" + slugKey(c.Name) + ".json"
+		seen[key] = true
+
+		export, err := a.db.ExportCollection(c.ID)
+		if err != nil {
+			return err
+		}
+
+		ifMatch := ""
+		if existing, err := backend.Get(key); err == nil {
+			ifMatch = existing.ETag
+		} else if err != storage.ErrNotFound {
+			return err
+		}
+
+		data, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if _, err := backend.Put(key, data, ifMatch); err != nil && err != storage.ErrPreconditionFailed {
+			return err
+		}
+		// A precondition failure means another device pushed this
+		// collection more recently; since collections don't merge, leave
+		// the remote copy alone rather than clobbering it.
+	}
+
+	remote, err := backend.List("collections/")
+	if err != nil {
+		return err
+	}
+	for _, obj := range remote {
+		if seen[obj.Key] {
+			continue
+		}
+
+		full, err := backend.Get(obj.Key)
+		if err != nil {
+			return err
+		}
+
+		var export database.CollectionExport
+		if err := json.Unmarshal(full.Data, &export); err != nil {
+			continue
+		}
+		if _, err := a.db.ImportCollection(&export); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncHistory pushes recent local history items to
+// "history/<deviceID>/<item.ID>.json", skipping items already present
+// remotely. Other devices' history is left visible in the shared bucket
+// for now but not replayed into the local history table — there's no
+// Store method that inserts a HistoryItem under a caller-chosen ID, and
+// fabricating one for sync alone isn't worth it yet; tracked as follow-up
+// work, mirroring replicator.pull's own documented scope.
+func (a *App) syncHistory(backend storage.Backend) error {
+	items, err := a.db.GetHistory(historyPushLimit, "")
+	if err != nil {
+		return err
+	}
+
+	prefix := "history/" + a.db.DeviceID() + "/"
+	for _, item := range items {
+		key := prefix + item.ID + ".json"
+
+		if _, err := backend.Get(key); err == nil {
+			continue // already pushed; history items never change once recorded
+		} else if err != storage.ErrNotFound {
+			return err
+		}
+
+		data, err := json.MarshalIndent(item, "", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := backend.Put(key, data, ""); err != nil && err != storage.ErrPreconditionFailed {
+			return err
+		}
+	}
+
+	return nil
+}
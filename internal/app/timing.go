@@ -0,0 +1,125 @@
+package app
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// traceState accumulates the absolute timestamps httptrace reports for a
+// single connection attempt. The state is reset at every redirect hop so
+// each hop's phases don't bleed into the next.
+type traceState struct {
+	mu sync.Mutex
+
+	start time.Time
+
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	wroteRequest              time.Time
+	firstByte                 time.Time
+}
+
+// newClientTrace builds an httptrace.ClientTrace that records into state.
+func newClientTrace(state *traceState) *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			state.mu.Lock()
+			state.dnsStart = time.Now()
+			state.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			state.mu.Lock()
+			state.dnsDone = time.Now()
+			state.mu.Unlock()
+		},
+		ConnectStart: func(network, addr string) {
+			state.mu.Lock()
+			state.connectStart = time.Now()
+			state.mu.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			state.mu.Lock()
+			state.connectDone = time.Now()
+			state.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			state.mu.Lock()
+			state.tlsStart = time.Now()
+			state.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			state.mu.Lock()
+			state.tlsDone = time.Now()
+			state.mu.Unlock()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			state.mu.Lock()
+			state.wroteRequest = time.Now()
+			state.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			state.mu.Lock()
+			state.firstByte = time.Now()
+			state.mu.Unlock()
+		},
+	}
+}
+
+// reset zeroes the state for the next hop, starting its clock at start.
+func (s *traceState) reset(start time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.start = start
+	s.dnsStart, s.dnsDone = time.Time{}, time.Time{}
+	s.connectStart, s.connectDone = time.Time{}, time.Time{}
+	s.tlsStart, s.tlsDone = time.Time{}, time.Time{}
+	s.wroteRequest = time.Time{}
+	s.firstByte = time.Time{}
+}
+
+// snapshot computes a Timings struct relative to the hop's start time.
+// downloadEnd is the moment the body finished being read (zero if not
+// applicable, e.g. when snapshotting a redirect hop).
+func (s *traceState) snapshot(downloadEnd time.Time) *Timings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms := func(t time.Time) int64 {
+		if t.IsZero() {
+			return 0
+		}
+		return t.Sub(s.start).Milliseconds()
+	}
+
+	t := &Timings{
+		DNSStartMs:          ms(s.dnsStart),
+		DNSDoneMs:           ms(s.dnsDone),
+		ConnectStartMs:      ms(s.connectStart),
+		ConnectDoneMs:       ms(s.connectDone),
+		TLSHandshakeStartMs: ms(s.tlsStart),
+		TLSHandshakeDoneMs:  ms(s.tlsDone),
+		WroteRequestMs:      ms(s.wroteRequest),
+		FirstByteAtMs:       ms(s.firstByte),
+	}
+
+	if !s.dnsStart.IsZero() && !s.dnsDone.IsZero() {
+		t.DNSMs = s.dnsDone.Sub(s.dnsStart).Milliseconds()
+	}
+	if !s.connectStart.IsZero() && !s.connectDone.IsZero() {
+		t.ConnectMs = s.connectDone.Sub(s.connectStart).Milliseconds()
+	}
+	if !s.tlsStart.IsZero() && !s.tlsDone.IsZero() {
+		t.TLSHandshakeMs = s.tlsDone.Sub(s.tlsStart).Milliseconds()
+	}
+	if !s.wroteRequest.IsZero() && !s.firstByte.IsZero() {
+		t.FirstByteMs = s.firstByte.Sub(s.wroteRequest).Milliseconds()
+	}
+	if !s.firstByte.IsZero() && !downloadEnd.IsZero() {
+		t.DownloadMs = downloadEnd.Sub(s.firstByte).Milliseconds()
+	}
+
+	return t
+}
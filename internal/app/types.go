@@ -1,5 +1,10 @@
 package app
 
+import (
+	"net/http"
+	"time"
+)
+
 // HTTPRequest represents the request from the frontend
 type HTTPRequest struct {
 	Method            string            `json:"method"`
@@ -13,6 +18,30 @@ type HTTPRequest struct {
 	ClientKeyPath     string            `json:"clientKeyPath"`     // path to client key
 	FollowRedirects   bool              `json:"followRedirects"`   // follow HTTP redirects (default true)
 	MaxRedirects      int               `json:"maxRedirects"`      // max redirects to follow (default 10)
+	DisableCookieJar  bool              `json:"disableCookieJar"`  // skip the per-environment cookie jar for this request
+	RetryMax          int               `json:"retryMax"`          // number of retries after the initial attempt, 0 = no retries
+	RetryOnStatuses   []int             `json:"retryOnStatuses"`   // response status codes that trigger a retry
+	RetryBackoffMs    int               `json:"retryBackoffMs"`    // base backoff, default 500ms
+	RetryMaxBackoffMs int               `json:"retryMaxBackoffMs"` // backoff ceiling, default 30s
+	RetryJitter       bool              `json:"retryJitter"`       // apply full-jitter randomization to the computed backoff
+
+	// Assertions are evaluated against this request's outcome. Only
+	// OpenWebSocket consumes these today (its wsMessageContains,
+	// wsMessageJson, and wsCloseCode types, evaluated in websocket.go against
+	// each streamed frame); the other Assertion.Type values are accepted here
+	// but not yet evaluated anywhere.
+	Assertions []Assertion `json:"assertions,omitempty"`
+}
+
+// Cookie represents a single stored cookie, scoped to an environment
+type Cookie struct {
+	Domain   string `json:"domain"`
+	Path     string `json:"path"`
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Expires  int64  `json:"expires"` // unix seconds, 0 = session cookie
+	Secure   bool   `json:"secure"`
+	HTTPOnly bool   `json:"httpOnly"`
 }
 
 // HTTPResponse represents the response to the frontend
@@ -23,7 +52,65 @@ type HTTPResponse struct {
 	Body          string            `json:"body"`
 	TimingMs      int64             `json:"timingMs"`
 	ContentLength int64             `json:"contentLength"`
+	Timings       *Timings          `json:"timings,omitempty"`
+	Hops          []HopTiming       `json:"hops,omitempty"`
+	Attempts      []AttemptInfo     `json:"attempts,omitempty"`
 	Error         string            `json:"error,omitempty"`
+
+	// UnresolvedVariables lists "{{var}}" references in the request's URL,
+	// headers, or body that had no value in any environment, so the UI can
+	// warn about a likely typo even though the request was still sent with
+	// those references left literal.
+	UnresolvedVariables []string `json:"unresolvedVariables,omitempty"`
+
+	// retryAfter and cookies are populated per-attempt for internal use by
+	// the retry policy and cookie jar; they aren't sent to the frontend.
+	retryAfter time.Duration
+	cookies    []*http.Cookie
+}
+
+// AttemptInfo records the outcome of a single try of a retried request.
+type AttemptInfo struct {
+	Attempt    int    `json:"attempt"` // 1-indexed
+	StatusCode int    `json:"statusCode"`
+	Error      string `json:"error,omitempty"`
+	TimingMs   int64  `json:"timingMs"`
+}
+
+// Timings breaks a single request/response round trip down into the phases
+// captured via httptrace.ClientTrace, each expressed in milliseconds.
+type Timings struct {
+	DNSMs          int64 `json:"dnsMs"`
+	ConnectMs      int64 `json:"connectMs"`
+	TLSHandshakeMs int64 `json:"tlsHandshakeMs"`
+	FirstByteMs    int64 `json:"firstByteMs"`
+	DownloadMs     int64 `json:"downloadMs"`
+
+	DNSStartMs          int64 `json:"dnsStartMs"`
+	DNSDoneMs           int64 `json:"dnsDoneMs"`
+	ConnectStartMs      int64 `json:"connectStartMs"`
+	ConnectDoneMs       int64 `json:"connectDoneMs"`
+	TLSHandshakeStartMs int64 `json:"tlsHandshakeStartMs"`
+	TLSHandshakeDoneMs  int64 `json:"tlsHandshakeDoneMs"`
+	WroteRequestMs      int64 `json:"wroteRequestMs"`
+	FirstByteAtMs       int64 `json:"firstByteAtMs"`
+}
+
+// HopTiming captures the trace for one hop of a followed redirect chain.
+type HopTiming struct {
+	URL        string   `json:"url"`
+	StatusCode int      `json:"statusCode"`
+	Timings    *Timings `json:"timings"`
+}
+
+// InterpolatedRequestPreview is the result of resolving a request's
+// "{{var}}" references without sending it, for a saved-request preview.
+type InterpolatedRequestPreview struct {
+	URL                 string            `json:"url"`
+	Headers             map[string]string `json:"headers"`
+	Body                string            `json:"body"`
+	UnresolvedVariables []string          `json:"unresolvedVariables,omitempty"`
+	Error               string            `json:"error,omitempty"`
 }
 
 // AppInfo contains application metadata
@@ -34,14 +121,28 @@ type AppInfo struct {
 
 // HistoryItem represents a saved request in history (re-exported from database)
 type HistoryItem struct {
-	ID         string            `json:"id"`
-	Method     string            `json:"method"`
-	URL        string            `json:"url"`
-	Headers    map[string]string `json:"headers"`
-	Body       string            `json:"body"`
-	StatusCode int               `json:"statusCode"`
-	TimingMs   int64             `json:"timingMs"`
-	CreatedAt  int64             `json:"createdAt"`
+	ID           string            `json:"id"`
+	Method       string            `json:"method"`
+	URL          string            `json:"url"`
+	Headers      map[string]string `json:"headers"`
+	Body         string            `json:"body"`
+	ResponseBody string            `json:"responseBody,omitempty"`
+	StatusCode   int               `json:"statusCode"`
+	TimingMs     int64             `json:"timingMs"`
+	CreatedAt    int64             `json:"createdAt"`
+	Snippet      string            `json:"snippet,omitempty"` // highlighted match, only set by SearchHistory
+}
+
+// SearchFilters narrows a SearchHistory query with structured constraints
+// on top of the free-text query; all fields are optional.
+type SearchFilters struct {
+	Methods         []string `json:"methods"`
+	StatusCodeRange [2]int   `json:"statusCodeRange"` // [0, 0] means unbounded
+	SinceUnix       int64    `json:"sinceUnix"`       // 0 means unbounded
+	UntilUnix       int64    `json:"untilUnix"`       // 0 means unbounded
+	MinDurationMs   int64    `json:"minDurationMs"`
+	MaxDurationMs   int64    `json:"maxDurationMs"`
+	CollectionID    string   `json:"collectionId"`
 }
 
 // Collection represents a folder for organizing saved requests
@@ -56,6 +157,7 @@ type Collection struct {
 type SavedRequest struct {
 	ID           string            `json:"id"`
 	CollectionID string            `json:"collectionId"`
+	FolderID     string            `json:"folderId,omitempty"` // empty means directly in the collection root
 	Name         string            `json:"name"`
 	Method       string            `json:"method"`
 	URL          string            `json:"url"`
@@ -65,6 +167,24 @@ type SavedRequest struct {
 	UpdatedAt    int64             `json:"updatedAt"`
 }
 
+// Folder is a nested grouping of saved requests within a collection
+// (re-exported from database).
+type Folder struct {
+	ID           string `json:"id"`
+	CollectionID string `json:"collectionId"`
+	ParentID     string `json:"parentId,omitempty"`
+	Name         string `json:"name"`
+	Path         string `json:"path"`
+	Position     int    `json:"position"`
+}
+
+// FolderNode is one assembled node of a collection's folder tree.
+type FolderNode struct {
+	Folder
+	Requests []SavedRequest `json:"requests"`
+	Children []*FolderNode  `json:"children"`
+}
+
 // SaveRequestInput is the input for saving a request to a collection
 type SaveRequestInput struct {
 	Name    string            `json:"name"`
@@ -85,6 +205,7 @@ type Environment struct {
 	ID        string `json:"id"`
 	Name      string `json:"name"`
 	IsActive  bool   `json:"isActive"`
+	IsGlobal  bool   `json:"isGlobal"`
 	CreatedAt int64  `json:"createdAt"`
 	UpdatedAt int64  `json:"updatedAt"`
 }
@@ -96,6 +217,8 @@ type EnvironmentVariable struct {
 	Key           string `json:"key"`
 	Value         string `json:"value"`
 	Enabled       bool   `json:"enabled"`
+	Type          string `json:"type"` // "string" (default) or "secret"; secret values are encrypted at rest
+	Tags          string `json:"tags"` // comma-separated, e.g. "debug,staging-only"
 	CreatedAt     int64  `json:"createdAt"`
 	UpdatedAt     int64  `json:"updatedAt"`
 }
@@ -106,10 +229,29 @@ type EnvironmentExport struct {
 	Variables []EnvironmentVariable `json:"variables"`
 }
 
+// EnvironmentRevision is a point-in-time snapshot of an environment's
+// variables, re-exported from database.
+type EnvironmentRevision struct {
+	ID            string                `json:"id"`
+	EnvironmentID string                `json:"environmentId"`
+	Revision      int                   `json:"revision"`
+	Author        string                `json:"author"`
+	Variables     []EnvironmentVariable `json:"variables"`
+	CreatedAt     int64                 `json:"createdAt"`
+}
+
+// EnvironmentDiff describes which variable keys were added, removed, or
+// changed between two environment revisions.
+type EnvironmentDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+}
+
 // Assertion represents a test assertion for a response
 type Assertion struct {
 	ID       string `json:"id"`
-	Type     string `json:"type"`     // "status", "responseTime", "bodyContains", "bodyJson", "headerExists", "headerEquals"
+	Type     string `json:"type"`     // "status", "responseTime", "bodyContains", "bodyJson", "headerExists", "headerEquals", "wsMessageContains", "wsMessageJson", "wsCloseCode"
 	Property string `json:"property"` // For JSON path or header name
 	Operator string `json:"operator"` // "equals", "notEquals", "contains", "lessThan", "greaterThan", "exists", "matches"
 	Expected string `json:"expected"` // Expected value
@@ -123,3 +265,43 @@ type AssertionResult struct {
 	Actual      string `json:"actual"`
 	Message     string `json:"message"`
 }
+
+// GRPCRequest describes a unary or server-streaming gRPC call
+type GRPCRequest struct {
+	Target        string            `json:"target"`    // host:port of the gRPC server
+	Service       string            `json:"service"`   // fully-qualified service name, e.g. pkg.Greeter
+	Method        string            `json:"method"`    // method name, e.g. SayHello
+	Payload       string            `json:"payload"`   // JSON payload, transcoded to protobuf via the method's input descriptor
+	Headers       map[string]string `json:"headers"`   // mapped onto outgoing gRPC metadata
+	ProtoPath     string            `json:"protoPath"` // not read by SendGRPCRequest: methods are always resolved through server reflection
+	UseWeb        bool              `json:"useWeb"`    // use gRPC-Web framing over HTTP/1.1 instead of native HTTP/2 gRPC
+	SkipSSLVerify bool              `json:"skipSslVerify"`
+	Timeout       int               `json:"timeout"` // seconds, 0 = default
+}
+
+// GRPCResponse is the decoded result of a gRPC call
+type GRPCResponse struct {
+	Message    string            `json:"message"`            // JSON-encoded unary reply
+	Messages   []string          `json:"messages,omitempty"` // JSON-encoded replies for server-streaming calls
+	Trailers   map[string]string `json:"trailers"`
+	StatusCode uint32            `json:"statusCode"` // google.golang.org/grpc/codes.Code
+	StatusName string            `json:"statusName"`
+	TimingMs   int64             `json:"timingMs"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// GRPCMethodDescriptor describes a single method on a discovered service
+type GRPCMethodDescriptor struct {
+	Name            string `json:"name"`
+	InputType       string `json:"inputType"`
+	OutputType      string `json:"outputType"`
+	ServerStreaming bool   `json:"serverStreaming"`
+	ClientStreaming bool   `json:"clientStreaming"`
+}
+
+// GRPCServiceDescriptor describes a service discovered via server reflection
+// (supplied .proto files or descriptor sets are not loaded)
+type GRPCServiceDescriptor struct {
+	Name    string                 `json:"name"`
+	Methods []GRPCMethodDescriptor `json:"methods"`
+}
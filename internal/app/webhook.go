@@ -0,0 +1,268 @@
+package app
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"volt-api/internal/database"
+
+	"github.com/google/uuid"
+)
+
+const (
+	webhookQueueSize        = 256 // buffered jobs before a delivery is dropped rather than blocking the caller
+	webhookWorkerCount      = 4
+	webhookMaxAttempts      = 3
+	webhookRetryBaseDelay   = 1 * time.Second
+	webhookRetryMaxDelay    = 10 * time.Second
+	webhookDeliveryTimeout  = 10 * time.Second
+	webhookBodyPreviewBytes = 2048
+)
+
+// WebhookConfig is the frontend-facing mirror of database.WebhookConfig.
+type WebhookConfig struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	URL           string `json:"url"`
+	AuthScheme    string `json:"authScheme"`
+	AuthToken     string `json:"authToken"`
+	SigningSecret string `json:"signingSecret"`
+	EventFilter   string `json:"eventFilter"`
+	MinStatusCode int    `json:"minStatusCode"`
+	Enabled       bool   `json:"enabled"`
+	CreatedAt     int64  `json:"createdAt"`
+	UpdatedAt     int64  `json:"updatedAt"`
+}
+
+func toAppWebhookConfig(w database.WebhookConfig) WebhookConfig {
+	return WebhookConfig{
+		ID:            w.ID,
+		Name:          w.Name,
+		URL:           w.URL,
+		AuthScheme:    w.AuthScheme,
+		AuthToken:     w.AuthToken,
+		SigningSecret: w.SigningSecret,
+		EventFilter:   w.EventFilter,
+		MinStatusCode: w.MinStatusCode,
+		Enabled:       w.Enabled,
+		CreatedAt:     w.CreatedAt,
+		UpdatedAt:     w.UpdatedAt,
+	}
+}
+
+// CreateWebhookConfig registers a new outbound webhook target.
+func (a *App) CreateWebhookConfig(name, url, authScheme, authToken, signingSecret, eventFilter string, minStatusCode int, enabled bool) (*WebhookConfig, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	id, err := a.db.CreateWebhookConfig(name, url, authScheme, authToken, signingSecret, eventFilter, minStatusCode, enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := a.db.GetWebhookConfig(id)
+	if err != nil {
+		return nil, err
+	}
+	result := toAppWebhookConfig(*cfg)
+	return &result, nil
+}
+
+// GetWebhookConfigs returns every configured webhook target.
+func (a *App) GetWebhookConfigs() []WebhookConfig {
+	if a.db == nil {
+		return []WebhookConfig{}
+	}
+
+	items, err := a.db.GetWebhookConfigs()
+	if err != nil {
+		return []WebhookConfig{}
+	}
+
+	result := make([]WebhookConfig, len(items))
+	for i, item := range items {
+		result[i] = toAppWebhookConfig(item)
+	}
+	return result
+}
+
+// UpdateWebhookConfig replaces a webhook target's fields wholesale.
+func (a *App) UpdateWebhookConfig(id, name, url, authScheme, authToken, signingSecret, eventFilter string, minStatusCode int, enabled bool) error {
+	if a.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	return a.db.UpdateWebhookConfig(id, name, url, authScheme, authToken, signingSecret, eventFilter, minStatusCode, enabled)
+}
+
+// DeleteWebhookConfig removes a webhook target.
+func (a *App) DeleteWebhookConfig(id string) error {
+	if a.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	return a.db.DeleteWebhookConfig(id)
+}
+
+// webhookEnvelope is the JSON body posted to every enabled webhook once a
+// request finishes.
+type webhookEnvelope struct {
+	ID                  string            `json:"id"`
+	Method              string            `json:"method"`
+	URL                 string            `json:"url"`
+	StatusCode          int               `json:"statusCode"`
+	TimingMs            int64             `json:"timingMs"`
+	RequestHeaders      map[string]string `json:"requestHeaders"`
+	ResponseHeaders     map[string]string `json:"responseHeaders"`
+	RequestBodyPreview  string            `json:"requestBodyPreview"`
+	ResponseBodyPreview string            `json:"responseBodyPreview"`
+}
+
+// webhookDelivery is one queued POST to a single webhook target.
+type webhookDelivery struct {
+	config   database.WebhookConfig
+	envelope webhookEnvelope
+}
+
+// startWebhookWorkers launches the fixed pool of goroutines that drain
+// a.webhookJobs, so a slow or dead receiver retries on its own goroutine
+// instead of blocking SendRequest's caller.
+func (a *App) startWebhookWorkers() {
+	for i := 0; i < webhookWorkerCount; i++ {
+		go func() {
+			for job := range a.webhookJobs {
+				deliverWebhookWithRetry(job)
+			}
+		}()
+	}
+}
+
+// notifyWebhooks fans a completed request out to every enabled webhook
+// whose event filter and minimum status code match, queuing one delivery
+// per target. Queuing never blocks: if a.webhookJobs is full, the delivery
+// is dropped rather than stalling the caller.
+func (a *App) notifyWebhooks(method string, request HTTPRequest, response HTTPResponse) {
+	if a.db == nil {
+		return
+	}
+
+	configs, err := a.db.GetEnabledWebhookConfigs()
+	if err != nil || len(configs) == 0 {
+		return
+	}
+
+	isError := response.Error != "" || response.StatusCode >= 400
+	envelope := webhookEnvelope{
+		ID:                  uuid.New().String(),
+		Method:              method,
+		URL:                 request.URL,
+		StatusCode:          response.StatusCode,
+		TimingMs:            response.TimingMs,
+		RequestHeaders:      request.Headers,
+		ResponseHeaders:     response.Headers,
+		RequestBodyPreview:  truncateForPreview(request.Body),
+		ResponseBodyPreview: truncateForPreview(response.Body),
+	}
+
+	for _, cfg := range configs {
+		if cfg.MinStatusCode > 0 && response.StatusCode < cfg.MinStatusCode {
+			continue
+		}
+		switch cfg.EventFilter {
+		case database.WebhookEventSuccess:
+			if isError {
+				continue
+			}
+		case database.WebhookEventError:
+			if !isError {
+				continue
+			}
+		}
+
+		job := webhookDelivery{config: cfg, envelope: envelope}
+		select {
+		case a.webhookJobs <- job:
+		default:
+			fmt.Printf("Warning: webhook delivery queue full, dropping delivery to %s\n", cfg.URL)
+		}
+	}
+}
+
+// truncateForPreview caps a request/response body at webhookBodyPreviewBytes
+// so the envelope stays small even for large payloads.
+func truncateForPreview(body string) string {
+	if len(body) <= webhookBodyPreviewBytes {
+		return body
+	}
+	return body[:webhookBodyPreviewBytes]
+}
+
+// deliverWebhookWithRetry POSTs job's envelope to job.config.URL, making at
+// most webhookMaxAttempts attempts in all, with exponential backoff between
+// them, and retrying only on a transport error or a 5xx response.
+func deliverWebhookWithRetry(job webhookDelivery) {
+	body, err := json.Marshal(job.envelope)
+	if err != nil {
+		return
+	}
+
+	delay := webhookRetryBaseDelay
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		statusCode, err := deliverWebhook(job.config, body)
+		if err == nil && statusCode < 500 {
+			return
+		}
+
+		if attempt == webhookMaxAttempts {
+			return
+		}
+		time.Sleep(delay)
+		delay *= 2
+		if delay > webhookRetryMaxDelay {
+			delay = webhookRetryMaxDelay
+		}
+	}
+}
+
+// deliverWebhook sends a single POST attempt, setting the configured auth
+// header and, if a signing secret is set, an X-Volt-Signature header with
+// the HMAC-SHA256 of the raw body.
+func deliverWebhook(cfg database.WebhookConfig, body []byte) (statusCode int, err error) {
+	req, err := http.NewRequest(http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	switch cfg.AuthScheme {
+	case "bearer":
+		if cfg.AuthToken != "" {
+			req.Header.Set("Authorization", "Bearer "+cfg.AuthToken)
+		}
+	case "basic":
+		if cfg.AuthToken != "" {
+			req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(cfg.AuthToken)))
+		}
+	}
+
+	if cfg.SigningSecret != "" {
+		mac := hmac.New(sha256.New, []byte(cfg.SigningSecret))
+		mac.Write(body)
+		req.Header.Set("X-Volt-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	client := &http.Client{Timeout: webhookDeliveryTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,240 @@
+package app
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// WSMessageEvent is emitted over the Wails runtime for every frame received
+// on a connection opened with OpenWebSocket.
+type WSMessageEvent struct {
+	ConnID    string `json:"connId"`
+	Message   string `json:"message"`
+	IsBinary  bool   `json:"isBinary"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// WSCloseEvent is emitted once a connection closes cleanly, whether by
+// server close or a local CloseWebSocket call.
+type WSCloseEvent struct {
+	ConnID string `json:"connId"`
+	Code   int    `json:"code"`
+	Reason string `json:"reason"`
+}
+
+// WSErrorEvent is emitted instead of WSCloseEvent when a connection's read
+// loop ends because of a transport error rather than a clean close.
+type WSErrorEvent struct {
+	ConnID string `json:"connId"`
+	Error  string `json:"error"`
+}
+
+// WSAssertionEvent is emitted alongside a WSMessageEvent or WSCloseEvent
+// whenever the connection has enabled ws* assertions, carrying every result
+// computed against that frame.
+type WSAssertionEvent struct {
+	ConnID  string            `json:"connId"`
+	Results []AssertionResult `json:"results"`
+}
+
+// OpenWebSocket dials a WebSocket endpoint, reusing the HTTP request's proxy,
+// TLS, and header settings, and returns an ID used to reference the
+// connection from SendWebSocketMessage/CloseWebSocket. The ID is also
+// registered in the same a.pending map SendRequestAsync uses, so
+// CancelRequest(id) closes the connection too.
+func (a *App) OpenWebSocket(request HTTPRequest) (string, error) {
+	if request.URL == "" {
+		return "", fmt.Errorf("URL is required")
+	}
+
+	parsedURL, err := url.Parse(request.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	switch parsedURL.Scheme {
+	case "ws", "wss":
+	case "http":
+		parsedURL.Scheme = "ws"
+	case "https":
+		parsedURL.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("URL must use the ws://, wss://, http://, or https:// scheme")
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if request.SkipSSLVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+	if request.ClientCertPath != "" && request.ClientKeyPath != "" {
+		cert, err := tls.LoadX509KeyPair(request.ClientCertPath, request.ClientKeyPath)
+		if err == nil {
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	dialer := &websocket.Dialer{
+		TLSClientConfig:  tlsConfig,
+		HandshakeTimeout: DefaultTimeout,
+	}
+	if request.ProxyURL != "" {
+		proxyURL, err := url.Parse(request.ProxyURL)
+		if err == nil {
+			dialer.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	header := make(http.Header)
+	for key, value := range request.Headers {
+		if strings.EqualFold(key, "host") {
+			continue
+		}
+		header.Set(key, value)
+	}
+
+	conn, _, err := dialer.Dial(parsedURL.String(), header)
+	if err != nil {
+		return "", fmt.Errorf("failed to open websocket: %w", err)
+	}
+	conn.SetReadLimit(MaxResponseBodySize)
+
+	connID := uuid.New().String()
+	a.wsMu.Lock()
+	a.wsConns[connID] = conn
+	a.wsAssertions[connID] = request.Assertions
+	a.wsMu.Unlock()
+
+	a.pendingMu.Lock()
+	a.pending[connID] = &pendingRequest{cancel: func() { conn.Close() }}
+	a.pendingMu.Unlock()
+
+	go a.readWebSocketLoop(connID, conn)
+
+	return connID, nil
+}
+
+// readWebSocketLoop streams incoming frames to the frontend as
+// "ws:message" events until the connection closes, then emits either
+// "ws:close" (a clean close, local or remote) or "ws:error" (a transport
+// failure).
+func (a *App) readWebSocketLoop(connID string, conn *websocket.Conn) {
+	a.wsMu.Lock()
+	assertions := a.wsAssertions[connID]
+	a.wsMu.Unlock()
+
+	defer func() {
+		a.wsMu.Lock()
+		delete(a.wsConns, connID)
+		delete(a.wsAssertions, connID)
+		a.wsMu.Unlock()
+		a.finishPending(connID)
+		conn.Close()
+	}()
+
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			var closeCode int
+			switch {
+			case isCloseError(err):
+				ce := err.(*websocket.CloseError)
+				closeCode = ce.Code
+				runtime.EventsEmit(a.ctx, "ws:close", WSCloseEvent{ConnID: connID, Code: ce.Code, Reason: ce.Text})
+			case isLocalClose(err):
+				// Our own CloseWebSocket (or a CancelRequest-driven
+				// conn.Close()) tore the connection down locally; that's an
+				// expected shutdown, not a transport error.
+				closeCode = websocket.CloseNormalClosure
+				runtime.EventsEmit(a.ctx, "ws:close", WSCloseEvent{ConnID: connID, Code: websocket.CloseNormalClosure})
+			default:
+				runtime.EventsEmit(a.ctx, "ws:error", WSErrorEvent{ConnID: connID, Error: err.Error()})
+				return
+			}
+			if results := evaluateWSCloseAssertions(assertions, closeCode); len(results) > 0 {
+				runtime.EventsEmit(a.ctx, "ws:assertion", WSAssertionEvent{ConnID: connID, Results: results})
+			}
+			return
+		}
+
+		runtime.EventsEmit(a.ctx, "ws:message", WSMessageEvent{
+			ConnID:    connID,
+			Message:   string(data),
+			IsBinary:  messageType == websocket.BinaryMessage,
+			Timestamp: time.Now().UnixMilli(),
+		})
+
+		if messageType == websocket.TextMessage {
+			if results := evaluateWSMessageAssertions(assertions, string(data)); len(results) > 0 {
+				runtime.EventsEmit(a.ctx, "ws:assertion", WSAssertionEvent{ConnID: connID, Results: results})
+			}
+		}
+	}
+}
+
+// isCloseError reports whether err is a *websocket.CloseError, i.e. the
+// peer sent a close frame.
+func isCloseError(err error) bool {
+	_, ok := err.(*websocket.CloseError)
+	return ok
+}
+
+// isLocalClose reports whether err is the "use of closed network
+// connection" error ReadMessage returns once something on our side has
+// closed the underlying connection.
+func isLocalClose(err error) bool {
+	return strings.Contains(err.Error(), "use of closed network connection")
+}
+
+// SendWebSocketMessage writes one frame to an open connection. kind is
+// "text", "binary", or "ping"; payload is ignored for "ping".
+func (a *App) SendWebSocketMessage(connID, kind, payload string) error {
+	a.wsMu.Lock()
+	conn, ok := a.wsConns[connID]
+	a.wsMu.Unlock()
+	if !ok {
+		return fmt.Errorf("no open websocket connection %s", connID)
+	}
+	if len(payload) > MaxResponseBodySize {
+		return fmt.Errorf("message too large (max %d MB)", MaxResponseBodySize/1024/1024)
+	}
+
+	switch kind {
+	case "text":
+		return conn.WriteMessage(websocket.TextMessage, []byte(payload))
+	case "binary":
+		return conn.WriteMessage(websocket.BinaryMessage, []byte(payload))
+	case "ping":
+		return conn.WriteMessage(websocket.PingMessage, nil)
+	default:
+		return fmt.Errorf("unknown websocket message kind %q (want text, binary, or ping)", kind)
+	}
+}
+
+// CloseWebSocket sends a close frame with the given code and reason,
+// removes the tracked connection, and unregisters it from a.pending.
+func (a *App) CloseWebSocket(connID string, code int, reason string) error {
+	a.wsMu.Lock()
+	conn, ok := a.wsConns[connID]
+	delete(a.wsConns, connID)
+	a.wsMu.Unlock()
+	if !ok {
+		return fmt.Errorf("no open websocket connection %s", connID)
+	}
+	defer a.finishPending(connID)
+
+	if code == 0 {
+		code = websocket.CloseNormalClosure
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	_ = conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason), deadline)
+	return conn.Close()
+}
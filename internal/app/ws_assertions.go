@@ -0,0 +1,167 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// evaluateWSMessageAssertions runs every enabled wsMessageContains and
+// wsMessageJson assertion against one received text frame, returning one
+// AssertionResult per assertion that applies. Assertions of any other type
+// are ignored, so the same slice can also be handed to
+// evaluateWSCloseAssertions without filtering it first.
+func evaluateWSMessageAssertions(assertions []Assertion, message string) []AssertionResult {
+	var results []AssertionResult
+	for _, a := range assertions {
+		if !a.Enabled {
+			continue
+		}
+		switch a.Type {
+		case "wsMessageContains":
+			passed := strings.Contains(message, a.Expected)
+			results = append(results, AssertionResult{
+				AssertionID: a.ID,
+				Passed:      passed,
+				Actual:      message,
+				Message:     wsAssertionMessage(fmt.Sprintf("message contains %q", a.Expected), passed),
+			})
+		case "wsMessageJson":
+			actual, ok := jsonPathLookup(message, a.Property)
+			if !ok {
+				results = append(results, AssertionResult{
+					AssertionID: a.ID,
+					Passed:      false,
+					Actual:      message,
+					Message:     fmt.Sprintf("message is not JSON or has no field %q", a.Property),
+				})
+				continue
+			}
+			results = append(results, evaluateOperatorAssertion(a, actual))
+		}
+	}
+	return results
+}
+
+// evaluateWSCloseAssertions runs every enabled wsCloseCode assertion against
+// the code a connection closed with.
+func evaluateWSCloseAssertions(assertions []Assertion, code int) []AssertionResult {
+	var results []AssertionResult
+	actual := strconv.Itoa(code)
+	for _, a := range assertions {
+		if !a.Enabled || a.Type != "wsCloseCode" {
+			continue
+		}
+		results = append(results, evaluateOperatorAssertion(a, actual))
+	}
+	return results
+}
+
+// evaluateOperatorAssertion applies a's Operator ("equals" if unset) to
+// actual vs. a.Expected and packages the outcome as an AssertionResult.
+func evaluateOperatorAssertion(a Assertion, actual string) AssertionResult {
+	operator := a.Operator
+	if operator == "" {
+		operator = "equals"
+	}
+	passed, err := evaluateOperator(operator, actual, a.Expected)
+	if err != nil {
+		return AssertionResult{AssertionID: a.ID, Passed: false, Actual: actual, Message: err.Error()}
+	}
+	return AssertionResult{
+		AssertionID: a.ID,
+		Passed:      passed,
+		Actual:      actual,
+		Message:     wsAssertionMessage(fmt.Sprintf("%s %s %q", a.Property, operator, a.Expected), passed),
+	}
+}
+
+// evaluateOperator compares actual against expected per Assertion.Operator's
+// documented values. lessThan/greaterThan parse both sides as float64;
+// everything else compares the raw strings.
+func evaluateOperator(operator, actual, expected string) (bool, error) {
+	switch operator {
+	case "equals":
+		return actual == expected, nil
+	case "notEquals":
+		return actual != expected, nil
+	case "contains":
+		return strings.Contains(actual, expected), nil
+	case "exists":
+		return actual != "", nil
+	case "matches":
+		re, err := regexp.Compile(expected)
+		if err != nil {
+			return false, fmt.Errorf("invalid assertion regexp %q: %w", expected, err)
+		}
+		return re.MatchString(actual), nil
+	case "lessThan", "greaterThan":
+		actualNum, err := strconv.ParseFloat(actual, 64)
+		if err != nil {
+			return false, fmt.Errorf("actual value %q is not numeric", actual)
+		}
+		expectedNum, err := strconv.ParseFloat(expected, 64)
+		if err != nil {
+			return false, fmt.Errorf("expected value %q is not numeric", expected)
+		}
+		if operator == "lessThan" {
+			return actualNum < expectedNum, nil
+		}
+		return actualNum > expectedNum, nil
+	default:
+		return false, fmt.Errorf("unknown assertion operator %q", operator)
+	}
+}
+
+// jsonPathLookup parses raw as JSON and walks a dot-separated path of object
+// keys (e.g. "user.id"), returning the value at that path as a string. An
+// empty path returns the whole document. ok is false if raw isn't valid
+// JSON or the path doesn't resolve to a value.
+func jsonPathLookup(raw, path string) (value string, ok bool) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return "", false
+	}
+	if path != "" {
+		for _, segment := range strings.Split(path, ".") {
+			m, isObject := data.(map[string]interface{})
+			if !isObject {
+				return "", false
+			}
+			data, ok = m[segment]
+			if !ok {
+				return "", false
+			}
+		}
+	}
+	return jsonValueString(data), true
+}
+
+// jsonValueString renders a value decoded by encoding/json as a comparable
+// string: strings pass through unquoted, nil becomes "", everything else
+// (numbers, bools, objects, arrays) is re-marshaled to its JSON form.
+func jsonValueString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case nil:
+		return ""
+	default:
+		b, err := json.Marshal(t)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
+
+// wsAssertionMessage formats the human-readable AssertionResult.Message
+// shared by every ws* assertion type.
+func wsAssertionMessage(check string, passed bool) string {
+	if passed {
+		return check + ": passed"
+	}
+	return check + ": failed"
+}
@@ -0,0 +1,101 @@
+package app
+
+import "testing"
+
+func TestEvaluateWSMessageAssertions_Contains(t *testing.T) {
+	assertions := []Assertion{
+		{ID: "a1", Type: "wsMessageContains", Expected: "pong", Enabled: true},
+		{ID: "a2", Type: "wsMessageContains", Expected: "missing", Enabled: true},
+		{ID: "a3", Type: "wsMessageContains", Expected: "pong", Enabled: false},
+	}
+
+	results := evaluateWSMessageAssertions(assertions, "ping pong")
+	if len(results) != 2 {
+		t.Fatalf("want 2 results (disabled assertion skipped), got %d", len(results))
+	}
+	if !results[0].Passed {
+		t.Errorf("a1 should pass, got %+v", results[0])
+	}
+	if results[1].Passed {
+		t.Errorf("a2 should fail, got %+v", results[1])
+	}
+}
+
+func TestEvaluateWSMessageAssertions_Json(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		property string
+		operator string
+		expected string
+		want     bool
+	}{
+		{"equals nested field", `{"user":{"id":"42"}}`, "user.id", "equals", "42", true},
+		{"notEquals mismatch", `{"status":"ok"}`, "status", "notEquals", "error", true},
+		{"numeric greaterThan", `{"count":10}`, "count", "greaterThan", "5", true},
+		{"missing property", `{"status":"ok"}`, "missing", "equals", "x", false},
+		{"not json", "not json", "status", "equals", "x", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertions := []Assertion{{ID: "a", Type: "wsMessageJson", Property: tt.property, Operator: tt.operator, Expected: tt.expected, Enabled: true}}
+			results := evaluateWSMessageAssertions(assertions, tt.message)
+			if len(results) != 1 {
+				t.Fatalf("want 1 result, got %d", len(results))
+			}
+			if results[0].Passed != tt.want {
+				t.Errorf("got %+v, want Passed=%v", results[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateWSCloseAssertions(t *testing.T) {
+	assertions := []Assertion{
+		{ID: "c1", Type: "wsCloseCode", Operator: "equals", Expected: "1000", Enabled: true},
+		{ID: "c2", Type: "wsCloseCode", Operator: "equals", Expected: "1006", Enabled: true},
+		{ID: "c3", Type: "wsMessageContains", Expected: "ignored", Enabled: true},
+	}
+
+	results := evaluateWSCloseAssertions(assertions, 1000)
+	if len(results) != 2 {
+		t.Fatalf("want 2 results (non-close assertion skipped), got %d", len(results))
+	}
+	if !results[0].Passed {
+		t.Errorf("c1 should pass, got %+v", results[0])
+	}
+	if results[1].Passed {
+		t.Errorf("c2 should fail, got %+v", results[1])
+	}
+}
+
+func TestEvaluateOperator(t *testing.T) {
+	tests := []struct {
+		operator, actual, expected string
+		want                       bool
+		wantErr                    bool
+	}{
+		{"equals", "foo", "foo", true, false},
+		{"notEquals", "foo", "bar", true, false},
+		{"contains", "foobar", "oob", true, false},
+		{"exists", "anything", "", true, false},
+		{"exists", "", "", false, false},
+		{"matches", "abc123", "^[a-z]+[0-9]+$", true, false},
+		{"lessThan", "3", "5", true, false},
+		{"greaterThan", "3", "5", false, false},
+		{"lessThan", "not-a-number", "5", false, true},
+		{"bogus", "a", "b", false, true},
+	}
+
+	for _, tt := range tests {
+		got, err := evaluateOperator(tt.operator, tt.actual, tt.expected)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s(%q,%q): err = %v, wantErr %v", tt.operator, tt.actual, tt.expected, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("%s(%q,%q) = %v, want %v", tt.operator, tt.actual, tt.expected, got, tt.want)
+		}
+	}
+}
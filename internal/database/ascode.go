@@ -0,0 +1,149 @@
+package database
+
+import (
+	"fmt"
+	"os"
+
+	"volt-api/internal/database/ascode"
+)
+
+// ============================================================================
+// Environment "as-code" sync (internal/database/ascode)
+// ============================================================================
+
+// SyncEnvironmentsToDisk writes every environment to dir as one JSON file
+// per environment, using the same secretPlaceholder substitution as
+// ExportEnvironment, so the directory can be committed to a git repo
+// without leaking decrypted secrets.
+func (d *sqliteStore) SyncEnvironmentsToDisk(dir string) error {
+	envs, err := d.GetEnvironments()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create ascode directory: %w", err)
+	}
+
+	for _, e := range envs {
+		export, err := d.ExportEnvironment(e.ID)
+		if err != nil {
+			return fmt.Errorf("failed to export environment %q: %w", e.Name, err)
+		}
+		if err := ascode.WriteEnvironment(dir, toEnvironmentFile(export)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncEnvironmentsFromDisk reconciles dir's environment files into the DB:
+// variables present on disk but absent in the DB are created, variables
+// present in the DB but absent from disk are deleted, and a file whose
+// environment name has no match in the DB is imported wholesale via
+// ImportEnvironment. With dryRun set, nothing is written — the returned
+// diffs (one per file) describe what would change.
+//
+// A disk value of secretPlaceholder for a secret variable never overwrites
+// a real secret: when reconciling an existing variable it's resolved back
+// to that variable's current decrypted value (the same "pull the
+// cleartext from the existing variable with the same key" rule
+// ImportEnvironment uses), and when it would otherwise create a brand new
+// variable there's no existing cleartext to resolve it against, so that
+// create is skipped.
+func (d *sqliteStore) SyncEnvironmentsFromDisk(dir string, dryRun bool) ([]ascode.Diff, error) {
+	files, err := ascode.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	envs, err := d.GetEnvironments()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]Environment, len(envs))
+	for _, e := range envs {
+		byName[e.Name] = e
+	}
+
+	diffs := make([]ascode.Diff, 0, len(files))
+	for _, file := range files {
+		existingEnv, ok := byName[file.Name]
+		if !ok {
+			diffs = append(diffs, ascode.Diff{EnvironmentName: file.Name, NewEnvironment: true, Creates: file.Variables})
+			if !dryRun {
+				if _, err := d.ImportEnvironment(toEnvironmentExport(file)); err != nil {
+					return diffs, fmt.Errorf("failed to import environment %q: %w", file.Name, err)
+				}
+			}
+			continue
+		}
+
+		vars, err := d.GetEnvironmentVariables(existingEnv.ID)
+		if err != nil {
+			return diffs, fmt.Errorf("failed to load variables for %q: %w", file.Name, err)
+		}
+		byKey := make(map[string]EnvironmentVariable, len(vars))
+		existingFiles := make([]ascode.VariableFile, len(vars))
+		for i, v := range vars {
+			byKey[v.Key] = v
+			existingFiles[i] = ascode.VariableFile{Key: v.Key, Value: v.Value, Enabled: v.Enabled, Type: v.Type}
+			if v.IsSecret() {
+				existingFiles[i].Value = secretPlaceholder
+			}
+		}
+
+		diff := ascode.DiffVariables(file, existingFiles)
+		diffs = append(diffs, diff)
+		if dryRun {
+			continue
+		}
+
+		for _, v := range diff.Creates {
+			if v.Type == VariableTypeSecret && v.Value == secretPlaceholder {
+				continue
+			}
+			if _, err := d.SetEnvironmentVariable(existingEnv.ID, v.Key, v.Value, v.Type, v.Enabled); err != nil {
+				return diffs, fmt.Errorf("failed to create variable %q in %q: %w", v.Key, file.Name, err)
+			}
+		}
+		for _, v := range diff.Updates {
+			value := v.Value
+			if v.Type == VariableTypeSecret && v.Value == secretPlaceholder {
+				existing, ok := byKey[v.Key]
+				if !ok {
+					continue
+				}
+				value = existing.Value
+			}
+			if _, err := d.SetEnvironmentVariable(existingEnv.ID, v.Key, value, v.Type, v.Enabled); err != nil {
+				return diffs, fmt.Errorf("failed to update variable %q in %q: %w", v.Key, file.Name, err)
+			}
+		}
+		for _, key := range diff.Deletes {
+			if v, ok := byKey[key]; ok {
+				if err := d.DeleteEnvironmentVariable(v.ID); err != nil {
+					return diffs, fmt.Errorf("failed to delete variable %q in %q: %w", key, file.Name, err)
+				}
+			}
+		}
+	}
+
+	return diffs, nil
+}
+
+func toEnvironmentFile(export *EnvironmentExport) ascode.EnvironmentFile {
+	vars := make([]ascode.VariableFile, len(export.Variables))
+	for i, v := range export.Variables {
+		vars[i] = ascode.VariableFile{Key: v.Key, Value: v.Value, Enabled: v.Enabled, Type: v.Type}
+	}
+	return ascode.EnvironmentFile{Name: export.Name, Variables: vars}
+}
+
+func toEnvironmentExport(file ascode.EnvironmentFile) *EnvironmentExport {
+	vars := make([]EnvironmentVariable, len(file.Variables))
+	for i, v := range file.Variables {
+		vars[i] = EnvironmentVariable{Key: v.Key, Value: v.Value, Enabled: v.Enabled, Type: v.Type}
+	}
+	return &EnvironmentExport{Name: file.Name, Variables: vars}
+}
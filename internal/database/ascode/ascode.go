@@ -0,0 +1,214 @@
+// Package ascode materializes environments and their variables to plain
+// JSON files on disk (one file per environment) and diffs a directory of
+// those files against a caller-supplied snapshot of what's currently
+// stored, so a Database can reconcile the two without this package needing
+// to know anything about SQLite, Postgres, or encryption. Secret values are
+// expected to already be placeholder-safe (see the database package's
+// secretPlaceholder) by the time they reach this package; ascode only ever
+// compares and writes whatever string it's given.
+package ascode
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// VariableFile is the on-disk representation of one environment variable.
+type VariableFile struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Enabled bool   `json:"enabled"`
+	Type    string `json:"type"`
+}
+
+// EnvironmentFile is the on-disk representation of one environment: its
+// name and all of its variables.
+type EnvironmentFile struct {
+	Name      string         `json:"name"`
+	Variables []VariableFile `json:"variables"`
+}
+
+var filenameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// filename returns the file name an environment named name is written to:
+// a lowercased, filesystem-safe slug plus ".json".
+func filename(name string) string {
+	slug := filenameUnsafe.ReplaceAllString(strings.ToLower(name), "-")
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		slug = "environment"
+	}
+	return slug + ".json"
+}
+
+// WriteEnvironment writes env to dir as "<slug-of-name>.json", overwriting
+// any file already there for that environment.
+func WriteEnvironment(dir string, env EnvironmentFile) error {
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal environment %q: %w", env.Name, err)
+	}
+	path := filepath.Join(dir, filename(env.Name))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadDir reads every "*.json" file directly inside dir into an
+// EnvironmentFile. Files that aren't valid EnvironmentFile JSON are
+// skipped rather than failing the whole read, since a directory meant for
+// git may contain a README or other unrelated files alongside them.
+func ReadDir(dir string) ([]EnvironmentFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ascode directory: %w", err)
+	}
+
+	files := make([]EnvironmentFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var file EnvironmentFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// Diff describes the variable-level changes needed to reconcile one
+// environment's on-disk file against its current state. NewEnvironment is
+// set when the environment itself doesn't exist yet, in which case Creates
+// holds every variable from the file and Updates/Deletes are unused.
+type Diff struct {
+	EnvironmentName string         `json:"environmentName"`
+	NewEnvironment  bool           `json:"newEnvironment"`
+	Creates         []VariableFile `json:"creates"`
+	Updates         []VariableFile `json:"updates"`
+	Deletes         []string       `json:"deletes"`
+}
+
+// IsEmpty reports whether applying d would change anything.
+func (d Diff) IsEmpty() bool {
+	return !d.NewEnvironment && len(d.Creates) == 0 && len(d.Updates) == 0 && len(d.Deletes) == 0
+}
+
+// DiffVariables compares file's variables against existing (the caller's
+// current snapshot for that same environment, keyed by variable Key) and
+// returns the Creates/Updates/Deletes needed to make existing match file.
+func DiffVariables(file EnvironmentFile, existing []VariableFile) Diff {
+	byKey := make(map[string]VariableFile, len(existing))
+	for _, v := range existing {
+		byKey[v.Key] = v
+	}
+
+	diff := Diff{EnvironmentName: file.Name}
+	seen := make(map[string]bool, len(file.Variables))
+	for _, v := range file.Variables {
+		seen[v.Key] = true
+		current, ok := byKey[v.Key]
+		if !ok {
+			diff.Creates = append(diff.Creates, v)
+			continue
+		}
+		if current != v {
+			diff.Updates = append(diff.Updates, v)
+		}
+	}
+	for _, v := range existing {
+		if !seen[v.Key] {
+			diff.Deletes = append(diff.Deletes, v.Key)
+		}
+	}
+	return diff
+}
+
+// Watcher polls dir at a fixed interval and calls onChange whenever a
+// ".json" file inside it is added, removed, or modified. This tree has no
+// filesystem-notification dependency, so polling by mtime is the simple,
+// dependency-free alternative to something like fsnotify.
+type Watcher struct {
+	dir      string
+	interval time.Duration
+	onChange func()
+
+	stop chan struct{}
+	seen map[string]time.Time
+}
+
+// NewWatcher builds a Watcher for dir. Call Start to begin polling.
+func NewWatcher(dir string, interval time.Duration, onChange func()) *Watcher {
+	return &Watcher{dir: dir, interval: interval, onChange: onChange, stop: make(chan struct{})}
+}
+
+// Start begins polling in a background goroutine. Stop ends it. The files
+// already in dir are recorded first, so they don't count as added.
+func (w *Watcher) Start() {
+	w.changed()
+	go w.loop()
+}
+
+// Stop ends the polling goroutine started by Start.
+func (w *Watcher) Stop() {
+	close(w.stop)
+}
+
+func (w *Watcher) loop() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if w.changed() {
+				w.onChange()
+			}
+		}
+	}
+}
+
+func (w *Watcher) changed() bool {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return false
+	}
+
+	current := make(map[string]time.Time, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		current[entry.Name()] = info.ModTime()
+	}
+
+	changed := len(current) != len(w.seen)
+	if !changed {
+		for name, mtime := range current {
+			prev, ok := w.seen[name]
+			if !ok || !prev.Equal(mtime) {
+				changed = true
+				break
+			}
+		}
+	}
+	w.seen = current
+	return changed
+}
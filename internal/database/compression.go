@@ -0,0 +1,259 @@
+package database
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// minCompressSize is the smallest body compressBody will bother running
+// through a codec; anything shorter almost never shrinks enough (if at
+// all) to be worth the codec header and base64 overhead.
+const minCompressSize = 512
+
+// Codec is a pluggable body-compression algorithm. Every stored,
+// compressed body is tagged with its codec's MagicPrefix (before base64
+// encoding), so decompressBody dispatches on whichever prefix it finds
+// instead of assuming a single algorithm — an operator can switch
+// VOLT_BODY_CODEC to a different codec without re-encoding rows a
+// previous codec already wrote.
+type Codec interface {
+	// Name identifies the codec, matching the VOLT_BODY_CODEC value that
+	// selects it.
+	Name() string
+	// MagicPrefix is prepended to every body this codec compresses, ahead
+	// of the base64 encoding, so it survives as plain text in storage.
+	MagicPrefix() string
+	// MinSize is the size threshold this codec expects compressBody to
+	// gate on; codecRegistry codecs all share minCompressSize today, but
+	// the interface leaves room for a codec with different overhead
+	// characteristics to report its own.
+	MinSize() int
+	Encode(data []byte) ([]byte, error)
+	Decode(data []byte) ([]byte, error)
+}
+
+// codecRegistry lists every codec decompressBody knows how to read,
+// keyed by Name so activeCodec can look one up by VOLT_BODY_CODEC.
+var codecRegistry = map[string]Codec{
+	"gzip":    gzipCodec{},
+	"zstd":    zstdCodec{},
+	"snappy":  snappyCodec{},
+	"deflate": deflateCodec{},
+}
+
+// compressedPrefix is gzipCodec's MagicPrefix, kept as its own constant
+// since gzip is the default codec (and was the only one) before
+// VOLT_BODY_CODEC existed; callers that only care about "is this body
+// compressed at all" under the default config can still check it.
+const compressedPrefix = "gzip:"
+
+// activeCodec returns the codec VOLT_BODY_CODEC names, falling back to
+// gzip (the default, and the only codec volt-api used before this
+// became configurable) if it's unset or names an unknown codec.
+func activeCodec() Codec {
+	if c, ok := codecRegistry[os.Getenv("VOLT_BODY_CODEC")]; ok {
+		return c
+	}
+	return codecRegistry["gzip"]
+}
+
+// compressBody compresses body with the active codec (see activeCodec) if
+// it's at least minCompressSize long and compressing it actually saves
+// space; otherwise body is returned unchanged. Bodies whose sampled
+// zero-byte ratio clears sparseZeroRatioThreshold (padded binary columns,
+// protobuf blobs with mostly-default fields) go through the sparse
+// bit-vector pass (see compressSparseBody) instead, which tends to beat
+// gzip alone on that kind of data.
+//
+// compressBody is compressBodyForClass using defaultCompressionClass; see
+// that function for the adaptive-skip and metrics behavior applied here.
+func compressBody(body string) string {
+	return compressBodyForClass(body, defaultCompressionClass)
+}
+
+// compressBodyForClass is compressBody's class-aware form. class buckets
+// the adaptive-skip ratio tracked in compression_metrics.go, so a payload
+// class that consistently fails to shrink (an image upload, an
+// already-gzipped JSON blob) stops costing CPU on compression attempts
+// once enough samples confirm it, while other classes are unaffected. No
+// caller threads a real class through yet — compressBody always passes
+// defaultCompressionClass, so today this is one shared history; a future
+// caller that knows its Content-Type or route can call this directly to
+// get its own.
+func compressBodyForClass(body, class string) string {
+	if len(body) < minCompressSize {
+		recordSkip("too_small")
+		return body
+	}
+
+	if ewmaFor(class).shouldSkip() {
+		recordSkip("adaptive_skip")
+		return body
+	}
+
+	data := []byte(body)
+	if sparseSampleZeroRatio(data) >= sparseZeroRatioThreshold {
+		if sparse := compressSparseBody(data); sparse != "" {
+			recordCompressed(class, len(body), len(sparse))
+			return sparse
+		}
+	}
+
+	codec := activeCodec()
+	encoded, err := codec.Encode([]byte(body))
+	if err != nil {
+		return body
+	}
+
+	compressed := codec.MagicPrefix() + base64.StdEncoding.EncodeToString(encoded)
+	if len(compressed) >= len(body) {
+		recordSkip("no_gain")
+		recordCompressed(class, len(body), len(body))
+		return body
+	}
+
+	recordCompressed(class, len(body), len(compressed))
+	return compressed
+}
+
+// decompressBody reverses compressBody, identifying the codec that
+// produced body by its MagicPrefix rather than assuming the currently
+// active one, so bodies written under a previous VOLT_BODY_CODEC setting
+// still decode correctly. sparsePrefix is checked first since the sparse
+// pass has its own framing, independent of the codec registry. A body
+// with no recognized prefix, or one whose payload fails to decode, is
+// returned unchanged.
+func decompressBody(body string) string {
+	if strings.HasPrefix(body, sparsePrefix) {
+		if out, ok := decompressSparseBody(body); ok {
+			return out
+		}
+		return body
+	}
+
+	for _, codec := range codecRegistry {
+		prefix := codec.MagicPrefix()
+		if !strings.HasPrefix(body, prefix) {
+			continue
+		}
+
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(body, prefix))
+		if err != nil {
+			return body
+		}
+		decoded, err := codec.Decode(raw)
+		if err != nil {
+			return body
+		}
+		return string(decoded)
+	}
+	return body
+}
+
+// gzipCodec is the codec volt-api used before compression became
+// pluggable, and remains the default.
+type gzipCodec struct{}
+
+func (gzipCodec) Name() string        { return "gzip" }
+func (gzipCodec) MagicPrefix() string { return compressedPrefix }
+func (gzipCodec) MinSize() int        { return minCompressSize }
+
+func (gzipCodec) Encode(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCodec) Decode(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// deflateCodec is gzip's container-less sibling: the same DEFLATE stream
+// without gzip's header/checksum overhead.
+type deflateCodec struct{}
+
+func (deflateCodec) Name() string        { return "deflate" }
+func (deflateCodec) MagicPrefix() string { return "flate:" }
+func (deflateCodec) MinSize() int        { return minCompressSize }
+
+func (deflateCodec) Encode(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (deflateCodec) Decode(data []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// zstdCodec trades gzip's ubiquity for Facebook's zstd algorithm, which
+// tends to compress faster and smaller at a comparable ratio.
+type zstdCodec struct{}
+
+func (zstdCodec) Name() string        { return "zstd" }
+func (zstdCodec) MagicPrefix() string { return "zstd:" }
+func (zstdCodec) MinSize() int        { return minCompressSize }
+
+func (zstdCodec) Encode(data []byte) ([]byte, error) {
+	w, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer w.Close()
+	return w.EncodeAll(data, nil), nil
+}
+
+func (zstdCodec) Decode(data []byte) ([]byte, error) {
+	r, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return r.DecodeAll(data, nil)
+}
+
+// snappyCodec favors encode/decode speed over compression ratio, for
+// operators who'd rather spend less CPU per request than save more bytes.
+type snappyCodec struct{}
+
+func (snappyCodec) Name() string        { return "snappy" }
+func (snappyCodec) MagicPrefix() string { return "snap:" }
+func (snappyCodec) MinSize() int        { return minCompressSize }
+
+func (snappyCodec) Encode(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
+func (snappyCodec) Decode(data []byte) ([]byte, error) {
+	return snappy.Decode(nil, data)
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// FuzzCompressRoundTrip asserts decompressBody(compressBody(x)) == x holds
+// for arbitrary input, not just the hand-picked bodies in
+// TestCompressDecompressRoundTrip.
+func FuzzCompressRoundTrip(f *testing.F) {
+	f.Add("")
+	f.Add("a")
+	f.Add(strings.Repeat("\x00", 2000))
+	f.Add(strings.Repeat("x", minCompressSize+100))
+	f.Add(`{"data":"` + strings.Repeat("hello world ", 200) + `"}`)
+	f.Add(strings.Repeat("\x00hello\x00world", 500))
+
+	f.Fuzz(func(t *testing.T, x string) {
+		got := decompressBody(compressBody(x))
+		if got != x {
+			t.Errorf("round-trip failed for input of length %d", len(x))
+		}
+	})
+}
+
+// FuzzDecompressBody feeds decompressBody arbitrary strings, with and
+// without compressedPrefix, seeded with the kind of pathological inputs
+// that trip up sparse bit-vector codecs in go-ethereum's bitutil fuzz
+// corpus: all-zero, single-byte, incompressible random, truncated
+// base64, and double-prefixed payloads. decompressBody must never panic,
+// and whatever it returns must either be the input unchanged (the
+// graceful-fallback path) or a valid UTF-8 string (a successfully
+// decoded body).
+func FuzzDecompressBody(f *testing.F) {
+	f.Add("")
+	f.Add("plain text body")
+	f.Add(compressedPrefix)
+	f.Add(compressedPrefix + "invalid-base64!!!")
+	f.Add(compressedPrefix + compressedPrefix)
+	f.Add(sparsePrefix + "invalid-base64!!!")
+	f.Add(strings.Repeat("\x00", 1000))
+	f.Add(compressedPrefix + strings.Repeat("A", 4))
+
+	f.Fuzz(func(t *testing.T, s string) {
+		result := decompressBody(s)
+		if result == s {
+			return
+		}
+		if !utf8.ValidString(result) {
+			t.Errorf("decompressBody produced non-UTF-8 output for input %q", s)
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultCompressionClass is the adaptive-skip bucket compressBody uses
+// when no caller-specific class is available (see compressBodyForClass).
+const defaultCompressionClass = "default"
+
+// compressEWMAAlpha weights how much each new compression ratio sample
+// moves a class's running average — low enough that one unusually good
+// or bad body doesn't immediately flip the adaptive-skip decision.
+const compressEWMAAlpha = 0.2
+
+// compressEWMAMinSamples is how many samples a class needs before its
+// EWMA is trusted enough to skip compression outright; below this, a
+// class always gets a real compression attempt regardless of its ratio
+// so far.
+const compressEWMAMinSamples = 5
+
+// compressSkipRatioThresholdDefault is the EWMA ratio (compressed bytes
+// over original bytes) at or above which a payload class is considered
+// to "consistently fail to shrink" and gets adaptively skipped.
+// Configurable via VOLT_COMPRESS_SKIP_THRESHOLD.
+const compressSkipRatioThresholdDefault = 0.95
+
+// compressSkipRatioThreshold reads VOLT_COMPRESS_SKIP_THRESHOLD, falling
+// back to compressSkipRatioThresholdDefault if it's unset or not a
+// sensible ratio.
+func compressSkipRatioThreshold() float64 {
+	if v := os.Getenv("VOLT_COMPRESS_SKIP_THRESHOLD"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 && f <= 1 {
+			return f
+		}
+	}
+	return compressSkipRatioThresholdDefault
+}
+
+// ewmaEntry tracks one payload class's running compression ratio.
+type ewmaEntry struct {
+	mu    sync.Mutex
+	ratio float64
+	n     int
+}
+
+func (e *ewmaEntry) shouldSkip() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.n >= compressEWMAMinSamples && e.ratio >= compressSkipRatioThreshold()
+}
+
+func (e *ewmaEntry) record(ratio float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.n == 0 {
+		e.ratio = ratio
+	} else {
+		e.ratio = compressEWMAAlpha*ratio + (1-compressEWMAAlpha)*e.ratio
+	}
+	e.n++
+}
+
+func (e *ewmaEntry) snapshot() (ratio float64, skip bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.ratio, e.n >= compressEWMAMinSamples && e.ratio >= compressSkipRatioThreshold()
+}
+
+var (
+	ewmaMu      sync.Mutex
+	ewmaByClass = map[string]*ewmaEntry{}
+)
+
+// ewmaFor returns class's running-ratio tracker, creating it on first use.
+func ewmaFor(class string) *ewmaEntry {
+	ewmaMu.Lock()
+	defer ewmaMu.Unlock()
+	e, ok := ewmaByClass[class]
+	if !ok {
+		e = &ewmaEntry{}
+		ewmaByClass[class] = e
+	}
+	return e
+}
+
+// compressionCounters are compressBody's lightweight stand-in for the
+// volt_compress_bytes_in / volt_compress_bytes_out / volt_compress_ratio
+// / volt_compress_skipped_total{reason=...} Prometheus series this was
+// asked for: volt-api is a Wails-bound desktop app with no HTTP server to
+// expose a /metrics endpoint from, so rather than pull in a full
+// Prometheus client for series nothing would ever scrape, these are
+// plain atomic counters that CompressionMetrics() exposes for a caller
+// to log or to back a real /metrics endpoint if one is ever added.
+var compressionCounters struct {
+	bytesIn  uint64
+	bytesOut uint64
+	skipped  sync.Map // reason string -> *uint64
+}
+
+func recordSkip(reason string) {
+	v, _ := compressionCounters.skipped.LoadOrStore(reason, new(uint64))
+	atomic.AddUint64(v.(*uint64), 1)
+}
+
+// recordCompressed records one compressBodyForClass attempt's bytes in
+// and out (whether or not compression was actually used — a same-size
+// result still feeds the class's adaptive-skip ratio) against both the
+// global counters and class's EWMA.
+func recordCompressed(class string, in, out int) {
+	atomic.AddUint64(&compressionCounters.bytesIn, uint64(in))
+	atomic.AddUint64(&compressionCounters.bytesOut, uint64(out))
+	if in > 0 {
+		ewmaFor(class).record(float64(out) / float64(in))
+	}
+}
+
+// CompressionMetricsSnapshot is a point-in-time read of compressBody's
+// counters.
+type CompressionMetricsSnapshot struct {
+	BytesIn  uint64            `json:"bytesIn"`
+	BytesOut uint64            `json:"bytesOut"`
+	Ratio    float64           `json:"ratio"`
+	Skipped  map[string]uint64 `json:"skipped"`
+}
+
+// CompressionMetrics returns a snapshot of compressBody's lifetime
+// counters: total bytes in and out, the resulting overall ratio, and how
+// many bodies were skipped under each reason (too_small, adaptive_skip,
+// no_gain).
+func CompressionMetrics() CompressionMetricsSnapshot {
+	in := atomic.LoadUint64(&compressionCounters.bytesIn)
+	out := atomic.LoadUint64(&compressionCounters.bytesOut)
+
+	ratio := 0.0
+	if in > 0 {
+		ratio = float64(out) / float64(in)
+	}
+
+	skipped := map[string]uint64{}
+	compressionCounters.skipped.Range(func(k, v any) bool {
+		skipped[k.(string)] = atomic.LoadUint64(v.(*uint64))
+		return true
+	})
+
+	return CompressionMetricsSnapshot{BytesIn: in, BytesOut: out, Ratio: ratio, Skipped: skipped}
+}
+
+// CompressionDebugHeaderValue formats the value an X-Volt-Compression
+// debug header would carry for class: which codec is active and that
+// class's current adaptive-skip ratio and decision. compressBody isn't
+// wired into the outbound request/response path today (see
+// compression.go), so nothing actually sets this header yet; it's
+// exposed here so that integration, whenever it lands, doesn't also need
+// to redesign the formatting.
+func CompressionDebugHeaderValue(class string) string {
+	ratio, skip := ewmaFor(class).snapshot()
+	return fmt.Sprintf("codec=%s;ratio=%.3f;skip=%t", activeCodec().Name(), ratio, skip)
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cookie represents a single stored cookie, scoped to an environment
+type Cookie struct {
+	Domain   string `json:"domain"`
+	Path     string `json:"path"`
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Expires  int64  `json:"expires"` // unix seconds, 0 = session cookie
+	Secure   bool   `json:"secure"`
+	HTTPOnly bool   `json:"httpOnly"`
+}
+
+// ============================================================================
+// Cookie Jar Methods
+// ============================================================================
+
+// UpsertCookie creates or updates a cookie for an environment/domain/path/name
+func (d *sqliteStore) UpsertCookie(environmentID string, c Cookie) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now().Unix()
+	secure, httpOnly := 0, 0
+	if c.Secure {
+		secure = 1
+	}
+	if c.HTTPOnly {
+		httpOnly = 1
+	}
+
+	_, err := d.db.Exec(
+		`INSERT INTO cookies (id, environment_id, domain, path, name, value, expires, secure, http_only, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(environment_id, domain, path, name) DO UPDATE SET
+		   value = excluded.value,
+		   expires = excluded.expires,
+		   secure = excluded.secure,
+		   http_only = excluded.http_only,
+		   updated_at = excluded.updated_at`,
+		uuid.New().String(), environmentID, c.Domain, c.Path, c.Name, c.Value, c.Expires, secure, httpOnly, now, now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert cookie: %w", err)
+	}
+	return nil
+}
+
+// GetCookies returns all cookies stored for an environment
+func (d *sqliteStore) GetCookies(environmentID string) ([]Cookie, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	rows, err := d.db.Query(
+		"SELECT domain, path, name, value, expires, secure, http_only FROM cookies WHERE environment_id = ? ORDER BY domain ASC, name ASC",
+		environmentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cookies: %w", err)
+	}
+	defer rows.Close()
+
+	cookies := make([]Cookie, 0)
+	for rows.Next() {
+		var c Cookie
+		var secure, httpOnly int
+		if err := rows.Scan(&c.Domain, &c.Path, &c.Name, &c.Value, &c.Expires, &secure, &httpOnly); err != nil {
+			continue
+		}
+		c.Secure = secure == 1
+		c.HTTPOnly = httpOnly == 1
+		cookies = append(cookies, c)
+	}
+
+	return cookies, nil
+}
+
+// DeleteCookie removes a single cookie by environment, domain, and name
+func (d *sqliteStore) DeleteCookie(environmentID, domain, name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err := d.db.Exec(
+		"DELETE FROM cookies WHERE environment_id = ? AND domain = ? AND name = ?",
+		environmentID, domain, name,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete cookie: %w", err)
+	}
+	return nil
+}
+
+// ClearCookies removes all cookies for an environment
+func (d *sqliteStore) ClearCookies(environmentID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err := d.db.Exec("DELETE FROM cookies WHERE environment_id = ?", environmentID)
+	if err != nil {
+		return fmt.Errorf("failed to clear cookies: %w", err)
+	}
+	return nil
+}
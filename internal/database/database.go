@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"sync"
@@ -13,18 +14,23 @@ import (
 
 	"github.com/google/uuid"
 	_ "modernc.org/sqlite"
+
+	"volt-api/internal/database/migrations"
+	"volt-api/internal/secrets"
 )
 
 // HistoryItem represents a saved request in history
 type HistoryItem struct {
-	ID         string            `json:"id"`
-	Method     string            `json:"method"`
-	URL        string            `json:"url"`
-	Headers    map[string]string `json:"headers"`
-	Body       string            `json:"body"`
-	StatusCode int               `json:"statusCode"`
-	TimingMs   int64             `json:"timingMs"`
-	CreatedAt  int64             `json:"createdAt"`
+	ID           string            `json:"id"`
+	Method       string            `json:"method"`
+	URL          string            `json:"url"`
+	Headers      map[string]string `json:"headers"`
+	Body         string            `json:"body"`
+	ResponseBody string            `json:"responseBody,omitempty"`
+	StatusCode   int               `json:"statusCode"`
+	TimingMs     int64             `json:"timingMs"`
+	CreatedAt    int64             `json:"createdAt"`
+	Snippet      string            `json:"snippet,omitempty"` // FTS5 match highlight, only set by SearchHistory
 }
 
 // Collection represents a folder for organizing saved requests
@@ -39,6 +45,7 @@ type Collection struct {
 type SavedRequest struct {
 	ID           string            `json:"id"`
 	CollectionID string            `json:"collectionId"`
+	FolderID     string            `json:"folderId,omitempty"` // empty means directly in the collection root
 	Name         string            `json:"name"`
 	Method       string            `json:"method"`
 	URL          string            `json:"url"`
@@ -48,10 +55,43 @@ type SavedRequest struct {
 	UpdatedAt    int64             `json:"updatedAt"`
 }
 
-// CollectionExport represents a collection with its requests for export/import
+// Folder is a nested grouping of saved requests within a collection. Path
+// is a slash-joined chain of ancestor folder IDs ending in this folder's
+// own ID (a materialized path), which lets MoveFolder rewrite an entire
+// subtree's path with a single UPDATE.
+type Folder struct {
+	ID           string `json:"id"`
+	CollectionID string `json:"collectionId"`
+	ParentID     string `json:"parentId,omitempty"`
+	Name         string `json:"name"`
+	Path         string `json:"path"`
+	Position     int    `json:"position"`
+}
+
+// FolderNode is one assembled node of a collection's folder tree, carrying
+// the saved requests filed directly under it and its nested subfolders.
+type FolderNode struct {
+	Folder
+	Requests []SavedRequest `json:"requests"`
+	Children []*FolderNode  `json:"children"`
+}
+
+// FolderExport is a portable, ID-free representation of one folder used by
+// CollectionExport, so a collection can be re-imported into a different
+// database without its folder IDs colliding with anything already there.
+type FolderExport struct {
+	Name     string         `json:"name"`
+	Requests []SavedRequest `json:"requests"`
+	Folders  []FolderExport `json:"folders,omitempty"`
+}
+
+// CollectionExport represents a collection with its requests and nested
+// folder tree for export/import. Requests holds only requests filed
+// directly in the collection root; nested ones live under Folders.
 type CollectionExport struct {
 	Name     string         `json:"name"`
 	Requests []SavedRequest `json:"requests"`
+	Folders  []FolderExport `json:"folders,omitempty"`
 }
 
 // Environment represents a named environment (e.g., Dev, Staging, Prod)
@@ -59,6 +99,7 @@ type Environment struct {
 	ID        string `json:"id"`
 	Name      string `json:"name"`
 	IsActive  bool   `json:"isActive"`
+	IsGlobal  bool   `json:"isGlobal"`
 	CreatedAt int64  `json:"createdAt"`
 	UpdatedAt int64  `json:"updatedAt"`
 }
@@ -70,18 +111,66 @@ type EnvironmentVariable struct {
 	Key           string `json:"key"`
 	Value         string `json:"value"`
 	Enabled       bool   `json:"enabled"`
+	Type          string `json:"type"` // VariableTypeString (default) or VariableTypeSecret
+	Tags          string `json:"tags"` // comma-separated, e.g. "debug,staging-only"
 	CreatedAt     int64  `json:"createdAt"`
 	UpdatedAt     int64  `json:"updatedAt"`
 }
 
+// Recognized EnvironmentVariable.Type values. A secret variable's Value is
+// encrypted at rest (see internal/secrets) and replaced by
+// secretPlaceholder on export.
+const (
+	VariableTypeString = "string"
+	VariableTypeSecret = "secret"
+)
+
+// secretPlaceholder stands in for a secret variable's value on export, so
+// exported environment JSON can be committed to source control without
+// leaking decrypted credentials. ImportEnvironment recognizes it and skips
+// that variable instead of storing it literally; it does not take a value
+// from an existing variable of the same key.
+const secretPlaceholder = "{{SECRET}}"
+
+// IsSecret reports whether v's value is stored encrypted at rest.
+func (v EnvironmentVariable) IsSecret() bool {
+	return v.Type == VariableTypeSecret
+}
+
+// EnvironmentRevision is a point-in-time snapshot of an environment's
+// variables, recorded every time SetEnvironmentVariable,
+// DeleteEnvironmentVariable, or ImportEnvironment changes it. Revision
+// numbers are monotonically increasing per environment, starting at 1.
+type EnvironmentRevision struct {
+	ID            string                `json:"id"`
+	EnvironmentID string                `json:"environmentId"`
+	Revision      int                   `json:"revision"`
+	Author        string                `json:"author"`
+	Variables     []EnvironmentVariable `json:"variables"`
+	CreatedAt     int64                 `json:"createdAt"`
+}
+
+// EnvironmentDiff describes the variable-level differences between two
+// environment revisions, keyed by variable Key.
+type EnvironmentDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+}
+
+// maxEnvironmentRevisions caps how many revisions are kept per
+// environment; snapshotEnvironment prunes older rows past this on every
+// write so the history doesn't grow unbounded.
+const maxEnvironmentRevisions = 50
+
 // EnvironmentExport represents an environment with its variables for export/import
 type EnvironmentExport struct {
 	Name      string                `json:"name"`
 	Variables []EnvironmentVariable `json:"variables"`
 }
 
-// Database handles SQLite operations for request history
-type Database struct {
+// sqliteStore handles SQLite operations for request history
+type sqliteStore struct {
 	db *sql.DB
 	mu sync.RWMutex // Protect concurrent access
 
@@ -91,6 +180,26 @@ type Database struct {
 	stmtGetByID   *sql.Stmt
 	stmtDelete    *sql.Stmt
 	stmtDeleteAll *sql.Stmt
+
+	// secretsMgr seals secret environment variables (and, optionally, history
+	// bodies matching secretBodyPatterns) at rest. It is nil when the OS
+	// keyring is unavailable and no passphrase fallback was configured; in
+	// that case secret variables cannot be created.
+	secretsMgr *secrets.Manager
+
+	secretBodyPatternsMu sync.RWMutex
+	secretBodyPatterns   []*regexp.Regexp
+
+	// ftsEnabled reports whether this SQLite build supports the FTS5
+	// extension the history_fts/saved_requests_fts migration depends on.
+	// SearchHistory falls back to a plain LIKE match when it's false.
+	ftsEnabled bool
+
+	// originID tags every changelog row this instance writes, so a
+	// replicator pulling from several devices can tell which one a change
+	// came from. It's stable across restarts (persisted alongside the
+	// database file) but has no meaning beyond that.
+	originID string
 }
 
 // getDataDir returns the appropriate data directory for the current OS
@@ -129,15 +238,52 @@ func getDataDir() (string, error) {
 	return dataDir, nil
 }
 
-// New creates and initializes the SQLite database with optimizations
-func New() (*Database, error) {
+// dbFilePath returns the path to the SQLite file in the OS data directory.
+func dbFilePath() (string, error) {
 	dataDir, err := getDataDir()
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	return filepath.Join(dataDir, "history.db"), nil
+}
+
+// loadOrCreateOriginID returns the stable device identifier a replicator
+// tags this instance's changelog rows with, minting and persisting one
+// alongside the database file on first run.
+func loadOrCreateOriginID() (string, error) {
+	dataDir, err := getDataDir()
+	if err != nil {
+		return "", err
+	}
+	idPath := filepath.Join(dataDir, "origin_id")
+
+	if existing, err := os.ReadFile(idPath); err == nil {
+		if id := strings.TrimSpace(string(existing)); id != "" {
+			return id, nil
+		}
+	}
+
+	id := uuid.New().String()
+	if err := os.WriteFile(idPath, []byte(id), 0600); err != nil {
+		return "", fmt.Errorf("failed to persist origin id: %w", err)
 	}
+	return id, nil
+}
 
-	dbPath := filepath.Join(dataDir, "history.db")
+// New creates and initializes the SQLite database with optimizations,
+// storing it in the OS-appropriate data directory.
+func New() (*sqliteStore, error) {
+	dbPath, err := dbFilePath()
+	if err != nil {
+		return nil, err
+	}
+	return newSQLiteStoreAt(dbPath)
+}
 
+// newSQLiteStoreAt opens and initializes a SQLite-backed sqliteStore at an
+// arbitrary path, for Open("sqlite://path") DSNs; New uses it with the
+// default OS data directory's history.db.
+func newSQLiteStoreAt(dbPath string) (*sqliteStore, error) {
 	// Open with optimized settings
 	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_synchronous=NORMAL&_cache_size=10000")
 	if err != nil {
@@ -149,13 +295,31 @@ func New() (*Database, error) {
 	db.SetMaxIdleConns(1)
 	db.SetConnMaxLifetime(time.Hour)
 
-	// Create tables
-	if err := createTables(db); err != nil {
+	// Enable foreign keys for this connection before migrating, since some
+	// migrations may rely on FK-backed cascades.
+	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
+	}
+
+	// Bring the schema up to date via the embedded migration set.
+	if err := migrations.Migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	originID, err := loadOrCreateOriginID()
+	if err != nil {
 		db.Close()
 		return nil, err
 	}
 
-	d := &Database{db: db}
+	d := &sqliteStore{db: db, originID: originID}
+
+	var ftsTableCount int
+	if err := db.QueryRow("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'history_fts'").Scan(&ftsTableCount); err == nil {
+		d.ftsEnabled = ftsTableCount > 0
+	}
 
 	// Prepare statements for better performance
 	if err := d.prepareStatements(); err != nil {
@@ -163,98 +327,55 @@ func New() (*Database, error) {
 		return nil, err
 	}
 
+	// Secret variable encryption degrades gracefully: if the OS keyring has
+	// no backend (e.g. a headless Linux box with no Secret Service), secret
+	// variables simply can't be created until one becomes available.
+	secretsMgr, err := secrets.NewManager(nil)
+	if err != nil {
+		fmt.Printf("Warning: secret-at-rest encryption unavailable: %v\n", err)
+	} else {
+		d.secretsMgr = secretsMgr
+	}
+
 	return d, nil
 }
 
-func createTables(db *sql.DB) error {
-	schema := `
-		CREATE TABLE IF NOT EXISTS history (
-			id TEXT PRIMARY KEY,
-			method TEXT NOT NULL,
-			url TEXT NOT NULL,
-			headers TEXT,
-			body TEXT,
-			status_code INTEGER,
-			timing_ms INTEGER,
-			created_at INTEGER NOT NULL
-		);
-
-		CREATE INDEX IF NOT EXISTS idx_history_created_at ON history(created_at DESC);
-
-		CREATE TABLE IF NOT EXISTS collections (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			created_at INTEGER NOT NULL,
-			updated_at INTEGER NOT NULL
-		);
-
-		CREATE INDEX IF NOT EXISTS idx_collections_name ON collections(name);
-
-		CREATE TABLE IF NOT EXISTS saved_requests (
-			id TEXT PRIMARY KEY,
-			collection_id TEXT NOT NULL,
-			name TEXT NOT NULL,
-			method TEXT NOT NULL,
-			url TEXT NOT NULL,
-			headers TEXT,
-			body TEXT,
-			created_at INTEGER NOT NULL,
-			updated_at INTEGER NOT NULL,
-			FOREIGN KEY (collection_id) REFERENCES collections(id) ON DELETE CASCADE
-		);
-
-		CREATE INDEX IF NOT EXISTS idx_saved_requests_collection ON saved_requests(collection_id);
-
-		CREATE TABLE IF NOT EXISTS environments (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL UNIQUE,
-			is_active INTEGER NOT NULL DEFAULT 0,
-			created_at INTEGER NOT NULL,
-			updated_at INTEGER NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS environment_variables (
-			id TEXT PRIMARY KEY,
-			environment_id TEXT NOT NULL,
-			key TEXT NOT NULL,
-			value TEXT NOT NULL,
-			enabled INTEGER NOT NULL DEFAULT 1,
-			created_at INTEGER NOT NULL,
-			updated_at INTEGER NOT NULL,
-			FOREIGN KEY (environment_id) REFERENCES environments(id) ON DELETE CASCADE,
-			UNIQUE(environment_id, key)
-		);
-
-		CREATE INDEX IF NOT EXISTS idx_env_vars_environment ON environment_variables(environment_id);
-	`
-
-	// Enable foreign keys
-	_, err := db.Exec("PRAGMA foreign_keys = ON")
+// MigrateDown reverts the on-disk database to schemaVersion, for use by the
+// CLI's -migrate-down flag and by tests that need to exercise a prior
+// schema. It opens and closes its own connection rather than going through
+// New, since the caller isn't expected to hold a live sqliteStore.
+func MigrateDown(schemaVersion int) error {
+	dbPath, err := dbFilePath()
 	if err != nil {
-		return fmt.Errorf("failed to enable foreign keys: %w", err)
+		return err
 	}
 
-	_, err = db.Exec(schema)
+	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_synchronous=NORMAL&_cache_size=10000")
 	if err != nil {
-		return fmt.Errorf("failed to create tables: %w", err)
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if err := migrations.MigrateDown(db, schemaVersion); err != nil {
+		return fmt.Errorf("failed to migrate down: %w", err)
 	}
 
 	return nil
 }
 
-func (d *Database) prepareStatements() error {
+func (d *sqliteStore) prepareStatements() error {
 	var err error
 
 	d.stmtInsert, err = d.db.Prepare(`
-		INSERT INTO history (id, method, url, headers, body, status_code, timing_ms, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO history (id, method, url, headers, body, response_body, status_code, timing_ms, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return err
 	}
 
 	d.stmtGetAll, err = d.db.Prepare(`
-		SELECT id, method, url, headers, body, status_code, timing_ms, created_at
+		SELECT id, method, url, headers, body, response_body, status_code, timing_ms, created_at
 		FROM history
 		ORDER BY created_at DESC
 		LIMIT ?
@@ -264,7 +385,7 @@ func (d *Database) prepareStatements() error {
 	}
 
 	d.stmtGetByID, err = d.db.Prepare(`
-		SELECT id, method, url, headers, body, status_code, timing_ms, created_at
+		SELECT id, method, url, headers, body, response_body, status_code, timing_ms, created_at
 		FROM history
 		WHERE id = ?
 	`)
@@ -285,8 +406,16 @@ func (d *Database) prepareStatements() error {
 	return nil
 }
 
+// DeviceID returns this machine's stable device identifier, the same
+// originID used to tag changelog rows and environment revisions, for
+// callers (e.g. the app package's storage sync) that need a per-device
+// identity.
+func (d *sqliteStore) DeviceID() string {
+	return d.originID
+}
+
 // Close closes the database connection
-func (d *Database) Close() error {
+func (d *sqliteStore) Close() error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -312,6 +441,87 @@ func (d *Database) Close() error {
 	return nil
 }
 
+// sqlExecer is satisfied by both *sql.DB and *sql.Tx, so logChange can be
+// called either as its own statement or folded into a caller's transaction.
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// logChange appends one row to the changelog table describing a mutation,
+// which a replicator later tails to build push/pull deltas for multi-device
+// sync. Callers that already run inside an explicit transaction should pass
+// that tx so the mutation and its changelog entry commit together; the
+// handful of callers still on single-statement prepared statements (history
+// writes) pass d.db instead, which logs the change as a best-effort,
+// slightly-non-atomic follow-up write. Wiring every mutator in through an
+// explicit transaction is left for a follow-up once more of the write path
+// needs it.
+func (d *sqliteStore) logChange(exec sqlExecer, entity, op string, payload interface{}) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal changelog payload: %w", err)
+	}
+	_, err = exec.Exec(
+		"INSERT INTO changelog (id, entity, op, payload_json, ts, origin_id) VALUES (?, ?, ?, ?, ?, ?)",
+		uuid.New().String(), entity, op, string(payloadJSON), time.Now().Unix(), d.originID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record changelog entry: %w", err)
+	}
+	return nil
+}
+
+// snapshotEnvironment records environmentID's current variables (read fresh
+// via environmentVariablesRawLocked, so callers don't have to assemble the
+// list themselves, and so secret values land in the snapshot sealed rather
+// than decrypted) as the next revision, then prunes revisions past
+// maxEnvironmentRevisions. The author is d.originID, the same identity
+// logChange tags changelog rows with. Called as a best-effort follow-up
+// write after SetEnvironmentVariable/DeleteEnvironmentVariable/
+// ImportEnvironment commit, mirroring logChange's own non-atomic,
+// single-statement convention. Callers must already hold d.mu (for Lock,
+// not RLock, since this issues writes).
+func (d *sqliteStore) snapshotEnvironment(environmentID string) error {
+	vars, err := d.environmentVariablesRawLocked(environmentID)
+	if err != nil {
+		return fmt.Errorf("failed to read variables for revision snapshot: %w", err)
+	}
+
+	snapshotJSON, err := json.Marshal(vars)
+	if err != nil {
+		return fmt.Errorf("failed to marshal revision snapshot: %w", err)
+	}
+
+	var nextRevision int
+	err = d.db.QueryRow(
+		"SELECT COALESCE(MAX(revision), 0) + 1 FROM environment_revisions WHERE environment_id = ?",
+		environmentID,
+	).Scan(&nextRevision)
+	if err != nil {
+		return fmt.Errorf("failed to compute next revision: %w", err)
+	}
+
+	_, err = d.db.Exec(
+		"INSERT INTO environment_revisions (id, environment_id, revision, author, snapshot_json, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		uuid.New().String(), environmentID, nextRevision, d.originID, string(snapshotJSON), time.Now().Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record environment revision: %w", err)
+	}
+
+	_, err = d.db.Exec(
+		`DELETE FROM environment_revisions WHERE environment_id = ? AND revision <= (
+			SELECT MAX(revision) - ? FROM environment_revisions WHERE environment_id = ?
+		)`,
+		environmentID, maxEnvironmentRevisions, environmentID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to prune old environment revisions: %w", err)
+	}
+
+	return nil
+}
+
 // sensitiveHeaders lists headers that should not be stored in history
 var sensitiveHeaders = map[string]bool{
 	"authorization":       true,
@@ -340,8 +550,75 @@ func filterSensitiveHeaders(headers map[string]string) map[string]string {
 	return filtered
 }
 
-// SaveRequest saves a request and response to history
-func (d *Database) SaveRequest(method, url string, headers map[string]string, body string, statusCode int, timingMs int64) (string, error) {
+// SetSecretBodyPatterns configures the field-name regexes that opt a
+// history body into at-rest encryption: a JSON body whose top-level keys
+// match any of these patterns is sealed the same way a secret environment
+// variable is. An empty list (the default) disables body encryption.
+func (d *sqliteStore) SetSecretBodyPatterns(patterns []string) error {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("invalid secret body pattern %q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+
+	d.secretBodyPatternsMu.Lock()
+	d.secretBodyPatterns = compiled
+	d.secretBodyPatternsMu.Unlock()
+	return nil
+}
+
+// bodyHasSecretField reports whether body is a JSON object with a
+// top-level key matching one of the configured secret body patterns.
+func (d *sqliteStore) bodyHasSecretField(headers map[string]string, body string) bool {
+	d.secretBodyPatternsMu.RLock()
+	patterns := d.secretBodyPatterns
+	d.secretBodyPatternsMu.RUnlock()
+	if len(patterns) == 0 || body == "" {
+		return false
+	}
+
+	if !isJSONContentType(headers) {
+		return false
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(body), &fields); err != nil {
+		return false
+	}
+
+	for key := range fields {
+		for _, pattern := range patterns {
+			if pattern.MatchString(key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isJSONContentType reports whether headers carries a JSON Content-Type.
+func isJSONContentType(headers map[string]string) bool {
+	for k, v := range headers {
+		if strings.ToLower(k) == "content-type" {
+			return strings.Contains(strings.ToLower(v), "application/json")
+		}
+	}
+	return false
+}
+
+// SaveRequest saves a request and response to history. If the request body
+// is JSON and contains a field matching a configured secret body pattern,
+// the request body is encrypted at rest the same way a secret environment
+// variable is; otherwise it, and the response body (which is never
+// secret-encrypted), are run through streamCompressBody, which only
+// touches bodies at least streamCompressThreshold long — ordinary bodies
+// stay exactly as given, so SearchHistory's FTS index still matches their
+// text. Only the rare multi-MB body pays the FTS-indexing cost of
+// becoming streamCompressBody's opaque compressed form.
+func (d *sqliteStore) SaveRequest(method, url string, headers map[string]string, body, responseBody string, statusCode int, timingMs int64) (string, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -355,16 +632,51 @@ func (d *Database) SaveRequest(method, url string, headers map[string]string, bo
 		headersJSON = []byte("{}")
 	}
 
-	_, err = d.stmtInsert.Exec(id, method, url, string(headersJSON), body, statusCode, timingMs, createdAt)
+	storedBody := body
+	if d.secretsMgr != nil && d.bodyHasSecretField(headers, body) {
+		if encrypted, err := d.secretsMgr.Encrypt(body); err == nil {
+			storedBody = encrypted
+		}
+	} else {
+		storedBody = streamCompressBody(storedBody)
+	}
+	storedResponseBody := streamCompressBody(responseBody)
+
+	_, err = d.stmtInsert.Exec(id, method, url, string(headersJSON), storedBody, storedResponseBody, statusCode, timingMs, createdAt)
 	if err != nil {
 		return "", fmt.Errorf("failed to save request: %w", err)
 	}
 
+	if err := d.logChange(d.db, "history", "insert", map[string]interface{}{"id": id}); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
 	return id, nil
 }
 
+// decryptHistoryBody transparently opens a body previously sealed by
+// SaveRequest; bodies without the enc:v1: prefix are returned unchanged.
+func (d *sqliteStore) decryptHistoryBody(body string) string {
+	if d.secretsMgr == nil || !secrets.IsEncrypted(body) {
+		return body
+	}
+	if plaintext, err := d.secretsMgr.Decrypt(body); err == nil {
+		return plaintext
+	}
+	return body
+}
+
+// decodeHistoryBody reverses whatever SaveRequest did to a stored request
+// body: a secret-encrypted body is unsealed by decryptHistoryBody, and a
+// streamCompressBody'd one is restored by decodeStoredBody. A given body
+// only ever went through one of the two, but running both is harmless,
+// since each is a no-op on input it doesn't recognize.
+func (d *sqliteStore) decodeHistoryBody(body string) string {
+	return decodeStoredBody(d.decryptHistoryBody(body))
+}
+
 // GetHistory retrieves request history with optional search filter
-func (d *Database) GetHistory(limit int, search string) ([]HistoryItem, error) {
+func (d *sqliteStore) GetHistory(limit int, search string) ([]HistoryItem, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
@@ -374,7 +686,7 @@ func (d *Database) GetHistory(limit int, search string) ([]HistoryItem, error) {
 	if search != "" {
 		// Use dynamic query for search
 		query := `
-			SELECT id, method, url, headers, body, status_code, timing_ms, created_at
+			SELECT id, method, url, headers, body, response_body, status_code, timing_ms, created_at
 			FROM history
 			WHERE url LIKE ? OR method LIKE ?
 			ORDER BY created_at DESC
@@ -395,8 +707,9 @@ func (d *Database) GetHistory(limit int, search string) ([]HistoryItem, error) {
 	for rows.Next() {
 		var item HistoryItem
 		var headersJSON string
+		var responseBody sql.NullString
 
-		err := rows.Scan(&item.ID, &item.Method, &item.URL, &headersJSON, &item.Body, &item.StatusCode, &item.TimingMs, &item.CreatedAt)
+		err := rows.Scan(&item.ID, &item.Method, &item.URL, &headersJSON, &item.Body, &responseBody, &item.StatusCode, &item.TimingMs, &item.CreatedAt)
 		if err != nil {
 			continue
 		}
@@ -407,6 +720,8 @@ func (d *Database) GetHistory(limit int, search string) ([]HistoryItem, error) {
 		if item.Headers == nil {
 			item.Headers = make(map[string]string)
 		}
+		item.Body = d.decodeHistoryBody(item.Body)
+		item.ResponseBody = decodeStoredBody(responseBody.String)
 
 		items = append(items, item)
 	}
@@ -415,14 +730,15 @@ func (d *Database) GetHistory(limit int, search string) ([]HistoryItem, error) {
 }
 
 // GetHistoryItem retrieves a single history item by ID
-func (d *Database) GetHistoryItem(id string) (*HistoryItem, error) {
+func (d *sqliteStore) GetHistoryItem(id string) (*HistoryItem, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
 	var item HistoryItem
 	var headersJSON string
+	var responseBody sql.NullString
 
-	err := d.stmtGetByID.QueryRow(id).Scan(&item.ID, &item.Method, &item.URL, &headersJSON, &item.Body, &item.StatusCode, &item.TimingMs, &item.CreatedAt)
+	err := d.stmtGetByID.QueryRow(id).Scan(&item.ID, &item.Method, &item.URL, &headersJSON, &item.Body, &responseBody, &item.StatusCode, &item.TimingMs, &item.CreatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get history item: %w", err)
 	}
@@ -433,12 +749,14 @@ func (d *Database) GetHistoryItem(id string) (*HistoryItem, error) {
 	if item.Headers == nil {
 		item.Headers = make(map[string]string)
 	}
+	item.Body = d.decodeHistoryBody(item.Body)
+	item.ResponseBody = decodeStoredBody(responseBody.String)
 
 	return &item, nil
 }
 
 // DeleteHistoryItem removes a single history entry
-func (d *Database) DeleteHistoryItem(id string) error {
+func (d *sqliteStore) DeleteHistoryItem(id string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -446,11 +764,14 @@ func (d *Database) DeleteHistoryItem(id string) error {
 	if err != nil {
 		return fmt.Errorf("failed to delete history item: %w", err)
 	}
+	if err := d.logChange(d.db, "history", "delete", map[string]interface{}{"id": id}); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
 	return nil
 }
 
 // ClearHistory removes all history entries
-func (d *Database) ClearHistory() error {
+func (d *sqliteStore) ClearHistory() error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -458,15 +779,225 @@ func (d *Database) ClearHistory() error {
 	if err != nil {
 		return fmt.Errorf("failed to clear history: %w", err)
 	}
+	if err := d.logChange(d.db, "history", "clear", map[string]interface{}{}); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
 	return nil
 }
 
+// SearchFilters narrows a SearchHistory free-text query with structured
+// constraints; every field is optional, and its zero value means
+// "unconstrained".
+type SearchFilters struct {
+	Methods         []string
+	StatusCodeRange [2]int // [0, 0] means unbounded
+	Since           time.Time
+	Until           time.Time
+	MinDurationMs   int64
+	MaxDurationMs   int64
+
+	// CollectionID is reserved for when saved requests join history in a
+	// single search; history entries aren't collection-scoped today, so
+	// this is currently ignored.
+	CollectionID string
+}
+
+// ftsColumns are the columns exposed by history_fts, keyed by the lowercase
+// name a user would type in a "field:value" query term.
+var ftsColumns = map[string]bool{
+	"url":           true,
+	"method":        true,
+	"headers":       true,
+	"body":          true,
+	"response_body": true,
+	"responsebody":  true,
+}
+
+// buildFTSQuery turns a free-text search box query into an FTS5 MATCH
+// expression. Bare terms are quoted so punctuation common in URLs (dots,
+// slashes, colons) isn't parsed as FTS5 query syntax, while a "field:value"
+// term naming a real column (e.g. "url:api.example.com") is passed through
+// with only its value quoted, since FTS5 already understands col:term as a
+// column filter.
+func buildFTSQuery(query string) string {
+	fields := strings.Fields(query)
+	terms := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if idx := strings.IndexByte(f, ':'); idx > 0 {
+			col := strings.ToLower(f[:idx])
+			if col == "responsebody" {
+				col = "response_body"
+			}
+			if ftsColumns[col] {
+				terms = append(terms, col+":"+quoteFTSTerm(f[idx+1:]))
+				continue
+			}
+		}
+		terms = append(terms, quoteFTSTerm(f))
+	}
+	return strings.Join(terms, " ")
+}
+
+// quoteFTSTerm wraps a term in double quotes so FTS5 treats it as a literal
+// phrase rather than parsing its punctuation as query syntax.
+func quoteFTSTerm(term string) string {
+	return `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+}
+
+// SearchHistory runs a full-text search over history's url, method,
+// headers, body, and response_body columns, narrowed by filters and ranked
+// by bm25 relevance. If this SQLite build has no FTS5 support, it falls
+// back to a LIKE match over url and method, same as GetHistory's search.
+func (d *sqliteStore) SearchHistory(query string, filters SearchFilters, limit int) ([]HistoryItem, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if limit <= 0 {
+		limit = 50
+	}
+	if !d.ftsEnabled {
+		return d.searchHistoryFallback(query, filters, limit)
+	}
+
+	conditions := []string{"history_fts MATCH ?"}
+	args := []interface{}{buildFTSQuery(query)}
+
+	conditions, args = appendHistoryFilters(conditions, args, filters, "h.")
+
+	sqlQuery := `
+		SELECT h.id, h.method, h.url, h.headers, h.body, h.response_body, h.status_code, h.timing_ms, h.created_at,
+		       snippet(history_fts, 3, '<mark>', '</mark>', '...', 12)
+		FROM history h
+		JOIN history_fts ON history_fts.rowid = h.rowid
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY bm25(history_fts)
+		LIMIT ?
+	`
+	args = append(args, limit)
+
+	rows, err := d.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search history: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]HistoryItem, 0, limit)
+	for rows.Next() {
+		var item HistoryItem
+		var headersJSON string
+		var responseBody sql.NullString
+
+		if err := rows.Scan(&item.ID, &item.Method, &item.URL, &headersJSON, &item.Body, &responseBody, &item.StatusCode, &item.TimingMs, &item.CreatedAt, &item.Snippet); err != nil {
+			continue
+		}
+
+		if headersJSON != "" {
+			json.Unmarshal([]byte(headersJSON), &item.Headers)
+		}
+		if item.Headers == nil {
+			item.Headers = make(map[string]string)
+		}
+		item.Body = d.decodeHistoryBody(item.Body)
+		item.ResponseBody = decodeStoredBody(responseBody.String)
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// searchHistoryFallback matches GetHistory's pre-FTS search behavior for
+// builds without the FTS5 extension: a substring match on url and method,
+// with the same structured filters applied.
+func (d *sqliteStore) searchHistoryFallback(query string, filters SearchFilters, limit int) ([]HistoryItem, error) {
+	conditions := []string{"(url LIKE ? OR method LIKE ?)"}
+	pattern := "%" + query + "%"
+	args := []interface{}{pattern, pattern}
+
+	conditions, args = appendHistoryFilters(conditions, args, filters, "")
+
+	sqlQuery := `
+		SELECT id, method, url, headers, body, response_body, status_code, timing_ms, created_at
+		FROM history
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY created_at DESC
+		LIMIT ?
+	`
+	args = append(args, limit)
+
+	rows, err := d.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search history: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]HistoryItem, 0, limit)
+	for rows.Next() {
+		var item HistoryItem
+		var headersJSON string
+		var responseBody sql.NullString
+
+		if err := rows.Scan(&item.ID, &item.Method, &item.URL, &headersJSON, &item.Body, &responseBody, &item.StatusCode, &item.TimingMs, &item.CreatedAt); err != nil {
+			continue
+		}
+
+		if headersJSON != "" {
+			json.Unmarshal([]byte(headersJSON), &item.Headers)
+		}
+		if item.Headers == nil {
+			item.Headers = make(map[string]string)
+		}
+		item.Body = d.decodeHistoryBody(item.Body)
+		item.ResponseBody = decodeStoredBody(responseBody.String)
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// appendHistoryFilters translates filters into SQL conditions and their
+// bound args, appending to conditions/args built so far. colPrefix lets
+// callers qualify column names (e.g. "h.") when the query joins history
+// against history_fts.
+func appendHistoryFilters(conditions []string, args []interface{}, filters SearchFilters, colPrefix string) ([]string, []interface{}) {
+	if len(filters.Methods) > 0 {
+		placeholders := make([]string, len(filters.Methods))
+		for i, m := range filters.Methods {
+			placeholders[i] = "?"
+			args = append(args, m)
+		}
+		conditions = append(conditions, colPrefix+"method IN ("+strings.Join(placeholders, ",")+")")
+	}
+	if filters.StatusCodeRange[0] != 0 || filters.StatusCodeRange[1] != 0 {
+		conditions = append(conditions, colPrefix+"status_code BETWEEN ? AND ?")
+		args = append(args, filters.StatusCodeRange[0], filters.StatusCodeRange[1])
+	}
+	if !filters.Since.IsZero() {
+		conditions = append(conditions, colPrefix+"created_at >= ?")
+		args = append(args, filters.Since.Unix())
+	}
+	if !filters.Until.IsZero() {
+		conditions = append(conditions, colPrefix+"created_at <= ?")
+		args = append(args, filters.Until.Unix())
+	}
+	if filters.MinDurationMs > 0 {
+		conditions = append(conditions, colPrefix+"timing_ms >= ?")
+		args = append(args, filters.MinDurationMs)
+	}
+	if filters.MaxDurationMs > 0 {
+		conditions = append(conditions, colPrefix+"timing_ms <= ?")
+		args = append(args, filters.MaxDurationMs)
+	}
+	return conditions, args
+}
+
 // ============================================================================
 // Collections Methods
 // ============================================================================
 
 // CreateCollection creates a new collection and returns its ID
-func (d *Database) CreateCollection(name string) (string, error) {
+func (d *sqliteStore) CreateCollection(name string) (string, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -485,7 +1016,7 @@ func (d *Database) CreateCollection(name string) (string, error) {
 }
 
 // GetCollections returns all collections
-func (d *Database) GetCollections() ([]Collection, error) {
+func (d *sqliteStore) GetCollections() ([]Collection, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
@@ -508,7 +1039,7 @@ func (d *Database) GetCollections() ([]Collection, error) {
 }
 
 // GetCollection returns a single collection by ID
-func (d *Database) GetCollection(id string) (*Collection, error) {
+func (d *sqliteStore) GetCollection(id string) (*Collection, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
@@ -523,7 +1054,7 @@ func (d *Database) GetCollection(id string) (*Collection, error) {
 }
 
 // RenameCollection updates a collection's name
-func (d *Database) RenameCollection(id, name string) error {
+func (d *sqliteStore) RenameCollection(id, name string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -536,7 +1067,7 @@ func (d *Database) RenameCollection(id, name string) error {
 }
 
 // DeleteCollection removes a collection and all its saved requests (via CASCADE)
-func (d *Database) DeleteCollection(id string) error {
+func (d *sqliteStore) DeleteCollection(id string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -552,7 +1083,7 @@ func (d *Database) DeleteCollection(id string) error {
 // ============================================================================
 
 // SaveRequestToCollection saves a request to a collection
-func (d *Database) SaveRequestToCollection(collectionID, name, method, url string, headers map[string]string, body string) (string, error) {
+func (d *sqliteStore) SaveRequestToCollection(collectionID, name, method, url string, headers map[string]string, body string) (string, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -575,13 +1106,20 @@ func (d *Database) SaveRequestToCollection(collectionID, name, method, url strin
 	return id, nil
 }
 
-// GetCollectionRequests returns all requests in a collection
-func (d *Database) GetCollectionRequests(collectionID string) ([]SavedRequest, error) {
+// GetCollectionRequests returns all requests in a collection, regardless of
+// which folder (if any) they're filed under.
+func (d *sqliteStore) GetCollectionRequests(collectionID string) ([]SavedRequest, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
+	return d.getCollectionRequestsLocked(collectionID)
+}
+
+// getCollectionRequestsLocked is GetCollectionRequests without acquiring
+// d.mu, for callers (GetFolderTree) that already hold it.
+func (d *sqliteStore) getCollectionRequestsLocked(collectionID string) ([]SavedRequest, error) {
 	rows, err := d.db.Query(
-		"SELECT id, collection_id, name, method, url, headers, body, created_at, updated_at FROM saved_requests WHERE collection_id = ? ORDER BY name ASC",
+		"SELECT id, collection_id, folder_id, name, method, url, headers, body, created_at, updated_at FROM saved_requests WHERE collection_id = ? ORDER BY name ASC",
 		collectionID,
 	)
 	if err != nil {
@@ -593,9 +1131,11 @@ func (d *Database) GetCollectionRequests(collectionID string) ([]SavedRequest, e
 	for rows.Next() {
 		var r SavedRequest
 		var headersJSON string
-		if err := rows.Scan(&r.ID, &r.CollectionID, &r.Name, &r.Method, &r.URL, &headersJSON, &r.Body, &r.CreatedAt, &r.UpdatedAt); err != nil {
+		var folderID sql.NullString
+		if err := rows.Scan(&r.ID, &r.CollectionID, &folderID, &r.Name, &r.Method, &r.URL, &headersJSON, &r.Body, &r.CreatedAt, &r.UpdatedAt); err != nil {
 			continue
 		}
+		r.FolderID = folderID.String
 		if headersJSON != "" {
 			json.Unmarshal([]byte(headersJSON), &r.Headers)
 		}
@@ -609,20 +1149,22 @@ func (d *Database) GetCollectionRequests(collectionID string) ([]SavedRequest, e
 }
 
 // GetSavedRequest returns a single saved request by ID
-func (d *Database) GetSavedRequest(id string) (*SavedRequest, error) {
+func (d *sqliteStore) GetSavedRequest(id string) (*SavedRequest, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
 	var r SavedRequest
 	var headersJSON string
+	var folderID sql.NullString
 	err := d.db.QueryRow(
-		"SELECT id, collection_id, name, method, url, headers, body, created_at, updated_at FROM saved_requests WHERE id = ?",
+		"SELECT id, collection_id, folder_id, name, method, url, headers, body, created_at, updated_at FROM saved_requests WHERE id = ?",
 		id,
-	).Scan(&r.ID, &r.CollectionID, &r.Name, &r.Method, &r.URL, &headersJSON, &r.Body, &r.CreatedAt, &r.UpdatedAt)
+	).Scan(&r.ID, &r.CollectionID, &folderID, &r.Name, &r.Method, &r.URL, &headersJSON, &r.Body, &r.CreatedAt, &r.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get saved request: %w", err)
 	}
 
+	r.FolderID = folderID.String
 	if headersJSON != "" {
 		json.Unmarshal([]byte(headersJSON), &r.Headers)
 	}
@@ -634,7 +1176,7 @@ func (d *Database) GetSavedRequest(id string) (*SavedRequest, error) {
 }
 
 // UpdateSavedRequest updates a saved request
-func (d *Database) UpdateSavedRequest(id, name, method, url string, headers map[string]string, body string) error {
+func (d *sqliteStore) UpdateSavedRequest(id, name, method, url string, headers map[string]string, body string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -655,7 +1197,7 @@ func (d *Database) UpdateSavedRequest(id, name, method, url string, headers map[
 }
 
 // MoveSavedRequest moves a saved request to a different collection
-func (d *Database) MoveSavedRequest(id, newCollectionID string) error {
+func (d *sqliteStore) MoveSavedRequest(id, newCollectionID string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -668,7 +1210,7 @@ func (d *Database) MoveSavedRequest(id, newCollectionID string) error {
 }
 
 // DeleteSavedRequest removes a saved request
-func (d *Database) DeleteSavedRequest(id string) error {
+func (d *sqliteStore) DeleteSavedRequest(id string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -680,45 +1222,274 @@ func (d *Database) DeleteSavedRequest(id string) error {
 }
 
 // ============================================================================
-// Export/Import Methods
+// Folders Methods
 // ============================================================================
 
-// ExportCollection exports a collection with all its requests
-func (d *Database) ExportCollection(id string) (*CollectionExport, error) {
-	collection, err := d.GetCollection(id)
-	if err != nil {
-		return nil, err
-	}
+// CreateFolder creates a folder inside a collection, nested under parentID
+// (empty for a root-level folder), and returns its ID. Its path is built
+// from the parent's path plus its own ID, which is what lets MoveFolder
+// rewrite an entire subtree in a single statement later.
+func (d *sqliteStore) CreateFolder(collectionID, parentID, name string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 
-	requests, err := d.GetCollectionRequests(id)
-	if err != nil {
-		return nil, err
+	id := uuid.New().String()
+	path := id
+
+	var parentIDArg interface{}
+	if parentID != "" {
+		var parentPath string
+		if err := d.db.QueryRow("SELECT path FROM folders WHERE id = ? AND collection_id = ?", parentID, collectionID).Scan(&parentPath); err != nil {
+			return "", fmt.Errorf("failed to look up parent folder: %w", err)
+		}
+		path = parentPath + "/" + id
+		parentIDArg = parentID
 	}
 
-	return &CollectionExport{
-		Name:     collection.Name,
-		Requests: requests,
-	}, nil
-}
+	var position int
+	if err := d.db.QueryRow(
+		"SELECT COALESCE(MAX(position), -1) + 1 FROM folders WHERE collection_id = ? AND parent_id IS ?",
+		collectionID, parentIDArg,
+	).Scan(&position); err != nil {
+		position = 0
+	}
 
-// ImportCollection imports a collection from export data
-func (d *Database) ImportCollection(data *CollectionExport) (string, error) {
-	// Create the collection
-	collectionID, err := d.CreateCollection(data.Name)
+	_, err := d.db.Exec(
+		"INSERT INTO folders (id, collection_id, parent_id, name, path, position) VALUES (?, ?, ?, ?, ?, ?)",
+		id, collectionID, parentIDArg, name, path, position,
+	)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to create folder: %w", err)
 	}
 
-	// Import all requests
-	for _, req := range data.Requests {
-		_, err := d.SaveRequestToCollection(collectionID, req.Name, req.Method, req.URL, req.Headers, req.Body)
-		if err != nil {
-			// Continue even if one request fails
-			continue
-		}
+	if err := d.logChange(d.db, "folder", "insert", map[string]interface{}{"id": id, "collectionId": collectionID}); err != nil {
+		fmt.Printf("Warning: %v\n", err)
 	}
 
-	return collectionID, nil
+	return id, nil
+}
+
+// MoveFolder reparents a folder under newParentID (empty to move it to the
+// collection root), rewriting the materialized path of its entire subtree
+// with a single UPDATE. It rejects moves that would nest the folder inside
+// its own subtree, which would otherwise create a cycle.
+func (d *sqliteStore) MoveFolder(id, newParentID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var oldPath, collectionID string
+	if err := d.db.QueryRow("SELECT path, collection_id FROM folders WHERE id = ?", id).Scan(&oldPath, &collectionID); err != nil {
+		return fmt.Errorf("failed to look up folder: %w", err)
+	}
+
+	var newParentPath string
+	var newParentIDArg interface{}
+	if newParentID != "" {
+		var newParentCollectionID string
+		if err := d.db.QueryRow("SELECT path, collection_id FROM folders WHERE id = ?", newParentID).Scan(&newParentPath, &newParentCollectionID); err != nil {
+			return fmt.Errorf("failed to look up new parent folder: %w", err)
+		}
+		if newParentCollectionID != collectionID {
+			return fmt.Errorf("cannot move folder %s into a folder from a different collection", id)
+		}
+		if newParentPath == oldPath || strings.HasPrefix(newParentPath, oldPath+"/") {
+			return fmt.Errorf("cannot move folder %s into its own subtree", id)
+		}
+		newParentIDArg = newParentID
+	}
+
+	folderIDSegment := id
+	newPath := folderIDSegment
+	if newParentPath != "" {
+		newPath = newParentPath + "/" + folderIDSegment
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		"UPDATE folders SET path = REPLACE(path, ?, ?) WHERE path = ? OR path LIKE ?",
+		oldPath, newPath, oldPath, oldPath+"/%",
+	); err != nil {
+		return fmt.Errorf("failed to rewrite folder subtree: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE folders SET parent_id = ? WHERE id = ?", newParentIDArg, id); err != nil {
+		return fmt.Errorf("failed to reparent folder: %w", err)
+	}
+	if err := d.logChange(tx, "folder", "move", map[string]interface{}{"id": id, "newParentId": newParentID}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetFolderTree assembles a collection's full folder hierarchy in one pass:
+// sorting folders by their materialized path guarantees every parent is
+// seen before its descendants, so each node can be attached to its parent
+// as it's read. The returned root node represents the collection itself
+// (empty ID) and holds the top-level folders plus any requests filed
+// directly in the collection root.
+func (d *sqliteStore) GetFolderTree(collectionID string) (*FolderNode, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	root := &FolderNode{
+		Folder:   Folder{CollectionID: collectionID},
+		Requests: []SavedRequest{},
+		Children: []*FolderNode{},
+	}
+
+	rows, err := d.db.Query(
+		"SELECT id, collection_id, parent_id, name, path, position FROM folders WHERE collection_id = ? ORDER BY path ASC",
+		collectionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query folders: %w", err)
+	}
+
+	nodes := make(map[string]*FolderNode)
+	for rows.Next() {
+		var f Folder
+		var parentID sql.NullString
+		if err := rows.Scan(&f.ID, &f.CollectionID, &parentID, &f.Name, &f.Path, &f.Position); err != nil {
+			continue
+		}
+		f.ParentID = parentID.String
+
+		node := &FolderNode{Folder: f, Requests: []SavedRequest{}, Children: []*FolderNode{}}
+		nodes[f.ID] = node
+
+		if parent, ok := nodes[f.ParentID]; f.ParentID != "" && ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			root.Children = append(root.Children, node)
+		}
+	}
+	rows.Close()
+
+	requests, err := d.getCollectionRequestsLocked(collectionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range requests {
+		if node, ok := nodes[r.FolderID]; r.FolderID != "" && ok {
+			node.Requests = append(node.Requests, r)
+		} else {
+			root.Requests = append(root.Requests, r)
+		}
+	}
+
+	return root, nil
+}
+
+// MoveSavedRequestToFolder files a saved request under folderID, or back to
+// the collection root when folderID is empty.
+func (d *sqliteStore) MoveSavedRequestToFolder(reqID, folderID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var folderIDArg interface{}
+	if folderID != "" {
+		folderIDArg = folderID
+	}
+
+	now := time.Now().Unix()
+	_, err := d.db.Exec("UPDATE saved_requests SET folder_id = ?, updated_at = ? WHERE id = ?", folderIDArg, now, reqID)
+	if err != nil {
+		return fmt.Errorf("failed to move saved request to folder: %w", err)
+	}
+	if err := d.logChange(d.db, "saved_request", "move", map[string]interface{}{"id": reqID, "folderId": folderID}); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// Export/Import Methods
+// ============================================================================
+
+// ExportCollection exports a collection along with its nested folder tree.
+// Requests holds only the ones filed directly in the collection root;
+// everything under a folder is nested inside Folders instead.
+func (d *sqliteStore) ExportCollection(id string) (*CollectionExport, error) {
+	collection, err := d.GetCollection(id)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := d.GetFolderTree(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CollectionExport{
+		Name:     collection.Name,
+		Requests: tree.Requests,
+		Folders:  exportFolderNodes(tree.Children),
+	}, nil
+}
+
+// exportFolderNodes converts assembled FolderNodes into the portable,
+// ID-free FolderExport shape CollectionExport carries, so re-importing
+// doesn't collide with folder IDs already present in the target database.
+func exportFolderNodes(nodes []*FolderNode) []FolderExport {
+	exports := make([]FolderExport, 0, len(nodes))
+	for _, n := range nodes {
+		exports = append(exports, FolderExport{
+			Name:     n.Name,
+			Requests: n.Requests,
+			Folders:  exportFolderNodes(n.Children),
+		})
+	}
+	return exports
+}
+
+// ImportCollection imports a collection from export data, recreating its
+// folder tree and filing each request under the right folder (or the
+// collection root).
+func (d *sqliteStore) ImportCollection(data *CollectionExport) (string, error) {
+	collectionID, err := d.CreateCollection(data.Name)
+	if err != nil {
+		return "", err
+	}
+
+	for _, req := range data.Requests {
+		if _, err := d.SaveRequestToCollection(collectionID, req.Name, req.Method, req.URL, req.Headers, req.Body); err != nil {
+			// Continue even if one request fails
+			continue
+		}
+	}
+
+	for _, folder := range data.Folders {
+		d.importFolderExport(collectionID, "", folder)
+	}
+
+	return collectionID, nil
+}
+
+// importFolderExport recreates one exported folder under parentID, giving
+// it a freshly generated ID, then recurses into its requests and
+// subfolders.
+func (d *sqliteStore) importFolderExport(collectionID, parentID string, folder FolderExport) {
+	folderID, err := d.CreateFolder(collectionID, parentID, folder.Name)
+	if err != nil {
+		return
+	}
+
+	for _, req := range folder.Requests {
+		reqID, err := d.SaveRequestToCollection(collectionID, req.Name, req.Method, req.URL, req.Headers, req.Body)
+		if err != nil {
+			continue
+		}
+		d.MoveSavedRequestToFolder(reqID, folderID)
+	}
+
+	for _, child := range folder.Folders {
+		d.importFolderExport(collectionID, folderID, child)
+	}
 }
 
 // ============================================================================
@@ -726,7 +1497,7 @@ func (d *Database) ImportCollection(data *CollectionExport) (string, error) {
 // ============================================================================
 
 // CreateEnvironment creates a new environment
-func (d *Database) CreateEnvironment(name string) (string, error) {
+func (d *sqliteStore) CreateEnvironment(name string) (string, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -745,11 +1516,11 @@ func (d *Database) CreateEnvironment(name string) (string, error) {
 }
 
 // GetEnvironments returns all environments
-func (d *Database) GetEnvironments() ([]Environment, error) {
+func (d *sqliteStore) GetEnvironments() ([]Environment, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
-	rows, err := d.db.Query("SELECT id, name, is_active, created_at, updated_at FROM environments ORDER BY name ASC")
+	rows, err := d.db.Query("SELECT id, name, is_active, is_global, created_at, updated_at FROM environments ORDER BY name ASC")
 	if err != nil {
 		return nil, fmt.Errorf("failed to query environments: %w", err)
 	}
@@ -758,11 +1529,12 @@ func (d *Database) GetEnvironments() ([]Environment, error) {
 	envs := make([]Environment, 0)
 	for rows.Next() {
 		var e Environment
-		var isActive int
-		if err := rows.Scan(&e.ID, &e.Name, &isActive, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		var isActive, isGlobal int
+		if err := rows.Scan(&e.ID, &e.Name, &isActive, &isGlobal, &e.CreatedAt, &e.UpdatedAt); err != nil {
 			continue
 		}
 		e.IsActive = isActive == 1
+		e.IsGlobal = isGlobal == 1
 		envs = append(envs, e)
 	}
 
@@ -770,31 +1542,32 @@ func (d *Database) GetEnvironments() ([]Environment, error) {
 }
 
 // GetEnvironment returns a single environment by ID
-func (d *Database) GetEnvironment(id string) (*Environment, error) {
+func (d *sqliteStore) GetEnvironment(id string) (*Environment, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
 	var e Environment
-	var isActive int
-	err := d.db.QueryRow("SELECT id, name, is_active, created_at, updated_at FROM environments WHERE id = ?", id).
-		Scan(&e.ID, &e.Name, &isActive, &e.CreatedAt, &e.UpdatedAt)
+	var isActive, isGlobal int
+	err := d.db.QueryRow("SELECT id, name, is_active, is_global, created_at, updated_at FROM environments WHERE id = ?", id).
+		Scan(&e.ID, &e.Name, &isActive, &isGlobal, &e.CreatedAt, &e.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get environment: %w", err)
 	}
 	e.IsActive = isActive == 1
+	e.IsGlobal = isGlobal == 1
 
 	return &e, nil
 }
 
 // GetActiveEnvironment returns the currently active environment
-func (d *Database) GetActiveEnvironment() (*Environment, error) {
+func (d *sqliteStore) GetActiveEnvironment() (*Environment, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
 	var e Environment
-	var isActive int
-	err := d.db.QueryRow("SELECT id, name, is_active, created_at, updated_at FROM environments WHERE is_active = 1").
-		Scan(&e.ID, &e.Name, &isActive, &e.CreatedAt, &e.UpdatedAt)
+	var isActive, isGlobal int
+	err := d.db.QueryRow("SELECT id, name, is_active, is_global, created_at, updated_at FROM environments WHERE is_active = 1").
+		Scan(&e.ID, &e.Name, &isActive, &isGlobal, &e.CreatedAt, &e.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // No active environment
@@ -802,12 +1575,36 @@ func (d *Database) GetActiveEnvironment() (*Environment, error) {
 		return nil, fmt.Errorf("failed to get active environment: %w", err)
 	}
 	e.IsActive = true
+	e.IsGlobal = isGlobal == 1
+
+	return &e, nil
+}
+
+// GetGlobalEnvironment returns the environment flagged as global (shared),
+// or nil if none is set. Its variables merge underneath the active
+// environment's for resolution purposes (see environment.Resolver).
+func (d *sqliteStore) GetGlobalEnvironment() (*Environment, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var e Environment
+	var isActive, isGlobal int
+	err := d.db.QueryRow("SELECT id, name, is_active, is_global, created_at, updated_at FROM environments WHERE is_global = 1").
+		Scan(&e.ID, &e.Name, &isActive, &isGlobal, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No global environment
+		}
+		return nil, fmt.Errorf("failed to get global environment: %w", err)
+	}
+	e.IsActive = isActive == 1
+	e.IsGlobal = true
 
 	return &e, nil
 }
 
 // SetActiveEnvironment sets an environment as active (only one can be active at a time)
-func (d *Database) SetActiveEnvironment(id string) error {
+func (d *sqliteStore) SetActiveEnvironment(id string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -829,8 +1626,30 @@ func (d *Database) SetActiveEnvironment(id string) error {
 	return nil
 }
 
+// SetGlobalEnvironment flags an environment as the shared/global one (only
+// one can hold the flag at a time). Pass "" to clear it.
+func (d *sqliteStore) SetGlobalEnvironment(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err := d.db.Exec("UPDATE environments SET is_global = 0")
+	if err != nil {
+		return fmt.Errorf("failed to clear global environment: %w", err)
+	}
+
+	if id != "" {
+		now := time.Now().Unix()
+		_, err = d.db.Exec("UPDATE environments SET is_global = 1, updated_at = ? WHERE id = ?", now, id)
+		if err != nil {
+			return fmt.Errorf("failed to set global environment: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // RenameEnvironment updates an environment's name
-func (d *Database) RenameEnvironment(id, name string) error {
+func (d *sqliteStore) RenameEnvironment(id, name string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -843,7 +1662,7 @@ func (d *Database) RenameEnvironment(id, name string) error {
 }
 
 // DeleteEnvironment removes an environment and all its variables (via CASCADE)
-func (d *Database) DeleteEnvironment(id string) error {
+func (d *sqliteStore) DeleteEnvironment(id string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -858,21 +1677,31 @@ func (d *Database) DeleteEnvironment(id string) error {
 // Environment Variables Methods
 // ============================================================================
 
-// SetEnvironmentVariable creates or updates a variable in an environment
-func (d *Database) SetEnvironmentVariable(environmentID, key, value string, enabled bool) (string, error) {
+// SetEnvironmentVariable creates or updates a variable in an environment. If
+// varType is VariableTypeSecret, value is sealed with the database's
+// secrets.Manager before it touches disk; this fails if no manager is
+// available (e.g. no OS keyring backend and no passphrase fallback
+// configured). An empty varType is treated as VariableTypeString.
+func (d *sqliteStore) SetEnvironmentVariable(environmentID, key, value, varType string, enabled bool) (string, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	now := time.Now().Unix()
-	enabledInt := 0
-	if enabled {
-		enabledInt = 1
+	if varType == "" {
+		varType = VariableTypeString
+	}
+
+	storedValue, err := d.sealIfSecret(value, varType)
+	if err != nil {
+		return "", err
 	}
 
+	now := time.Now().Unix()
+	enabledInt := boolToInt(enabled)
+
 	// Try to update first
 	result, err := d.db.Exec(
-		"UPDATE environment_variables SET value = ?, enabled = ?, updated_at = ? WHERE environment_id = ? AND key = ?",
-		value, enabledInt, now, environmentID, key,
+		"UPDATE environment_variables SET value = ?, enabled = ?, type = ?, updated_at = ? WHERE environment_id = ? AND key = ?",
+		storedValue, enabledInt, varType, now, environmentID, key,
 	)
 	if err != nil {
 		return "", fmt.Errorf("failed to update variable: %w", err)
@@ -886,29 +1715,97 @@ func (d *Database) SetEnvironmentVariable(environmentID, key, value string, enab
 		if err != nil {
 			return "", err
 		}
+		if err := d.snapshotEnvironment(environmentID); err != nil {
+			return "", err
+		}
 		return id, nil
 	}
 
 	// Insert new variable
 	id := uuid.New().String()
 	_, err = d.db.Exec(
-		"INSERT INTO environment_variables (id, environment_id, key, value, enabled, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
-		id, environmentID, key, value, enabledInt, now, now,
+		"INSERT INTO environment_variables (id, environment_id, key, value, enabled, type, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		id, environmentID, key, storedValue, enabledInt, varType, now, now,
 	)
 	if err != nil {
 		return "", fmt.Errorf("failed to create variable: %w", err)
 	}
 
+	if err := d.snapshotEnvironment(environmentID); err != nil {
+		return "", err
+	}
 	return id, nil
 }
 
-// GetEnvironmentVariables returns all variables for an environment
-func (d *Database) GetEnvironmentVariables(environmentID string) ([]EnvironmentVariable, error) {
+// sealIfSecret encrypts value via d.secretsMgr when varType is
+// VariableTypeSecret, returning an error if encryption was requested but no
+// manager is available.
+func (d *sqliteStore) sealIfSecret(value, varType string) (string, error) {
+	if varType != VariableTypeSecret {
+		return value, nil
+	}
+	if d.secretsMgr == nil {
+		return "", fmt.Errorf("cannot store secret variable: encryption manager is unavailable")
+	}
+	return d.secretsMgr.Encrypt(value)
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// GetEnvironmentVariables returns all variables for an environment, with
+// secret values transparently decrypted.
+func (d *sqliteStore) GetEnvironmentVariables(environmentID string) ([]EnvironmentVariable, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
+	return d.environmentVariablesLocked(environmentID)
+}
+
+// environmentVariablesLocked is GetEnvironmentVariables' query logic without
+// the RLock, for callers that already hold d.mu themselves. See
+// environmentVariablesRawLocked for the sealed-value sibling snapshotEnvironment
+// uses instead, so decrypted secrets never get written back out to disk.
+func (d *sqliteStore) environmentVariablesLocked(environmentID string) ([]EnvironmentVariable, error) {
+	rows, err := d.db.Query(
+		"SELECT id, environment_id, key, value, enabled, type, tags, created_at, updated_at FROM environment_variables WHERE environment_id = ? ORDER BY key ASC",
+		environmentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query variables: %w", err)
+	}
+	defer rows.Close()
+
+	vars := make([]EnvironmentVariable, 0)
+	for rows.Next() {
+		var v EnvironmentVariable
+		var enabled int
+		if err := rows.Scan(&v.ID, &v.EnvironmentID, &v.Key, &v.Value, &enabled, &v.Type, &v.Tags, &v.CreatedAt, &v.UpdatedAt); err != nil {
+			continue
+		}
+		v.Enabled = enabled == 1
+		if v.IsSecret() && d.secretsMgr != nil {
+			if plaintext, err := d.secretsMgr.Decrypt(v.Value); err == nil {
+				v.Value = plaintext
+			}
+		}
+		vars = append(vars, v)
+	}
+
+	return vars, nil
+}
+
+// environmentVariablesRawLocked is environmentVariablesLocked without the
+// decrypt step: secret values come back exactly as stored (sealed), for
+// callers like snapshotEnvironment that must persist what's in the
+// environment_variables table rather than its decrypted form.
+func (d *sqliteStore) environmentVariablesRawLocked(environmentID string) ([]EnvironmentVariable, error) {
 	rows, err := d.db.Query(
-		"SELECT id, environment_id, key, value, enabled, created_at, updated_at FROM environment_variables WHERE environment_id = ? ORDER BY key ASC",
+		"SELECT id, environment_id, key, value, enabled, type, tags, created_at, updated_at FROM environment_variables WHERE environment_id = ? ORDER BY key ASC",
 		environmentID,
 	)
 	if err != nil {
@@ -920,7 +1817,7 @@ func (d *Database) GetEnvironmentVariables(environmentID string) ([]EnvironmentV
 	for rows.Next() {
 		var v EnvironmentVariable
 		var enabled int
-		if err := rows.Scan(&v.ID, &v.EnvironmentID, &v.Key, &v.Value, &enabled, &v.CreatedAt, &v.UpdatedAt); err != nil {
+		if err := rows.Scan(&v.ID, &v.EnvironmentID, &v.Key, &v.Value, &enabled, &v.Type, &v.Tags, &v.CreatedAt, &v.UpdatedAt); err != nil {
 			continue
 		}
 		v.Enabled = enabled == 1
@@ -930,13 +1827,199 @@ func (d *Database) GetEnvironmentVariables(environmentID string) ([]EnvironmentV
 	return vars, nil
 }
 
+// GetEnvironmentVariablesByTag returns environmentID's variables whose
+// comma-separated Tags include tag exactly.
+func (d *sqliteStore) GetEnvironmentVariablesByTag(environmentID, tag string) ([]EnvironmentVariable, error) {
+	vars, err := d.GetEnvironmentVariables(environmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]EnvironmentVariable, 0, len(vars))
+	for _, v := range vars {
+		if hasTag(v.Tags, tag) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered, nil
+}
+
+// SetVariableTags replaces a variable's comma-separated tag list.
+func (d *sqliteStore) SetVariableTags(id, tags string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err := d.db.Exec("UPDATE environment_variables SET tags = ?, updated_at = ? WHERE id = ?", tags, time.Now().Unix(), id)
+	if err != nil {
+		return fmt.Errorf("failed to set variable tags: %w", err)
+	}
+	return nil
+}
+
+// EnableEnvironmentVariables enables every variable in ids inside a single
+// transaction, so a bulk toggle either fully applies or not at all.
+func (d *sqliteStore) EnableEnvironmentVariables(ids []string) error {
+	return d.bulkSetEnabled(ids, true)
+}
+
+// DisableEnvironmentVariables disables every variable in ids inside a
+// single transaction.
+func (d *sqliteStore) DisableEnvironmentVariables(ids []string) error {
+	return d.bulkSetEnabled(ids, false)
+}
+
+func (d *sqliteStore) bulkSetEnabled(ids []string, enabled bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, 0, len(ids)+2)
+	args = append(args, boolToInt(enabled), time.Now().Unix())
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+
+	query := fmt.Sprintf(
+		"UPDATE environment_variables SET enabled = ?, updated_at = ? WHERE id IN (%s)",
+		strings.Join(placeholders, ","),
+	)
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to bulk update variables: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// BulkDeleteEnvironmentVariables removes every variable in ids inside a
+// single transaction.
+func (d *sqliteStore) BulkDeleteEnvironmentVariables(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := fmt.Sprintf("DELETE FROM environment_variables WHERE id IN (%s)", strings.Join(placeholders, ","))
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to bulk delete variables: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// hasTag reports whether tag appears verbatim in tagsCSV's comma-separated
+// list.
+func hasTag(tagsCSV, tag string) bool {
+	for _, t := range strings.Split(tagsCSV, ",") {
+		if strings.TrimSpace(t) == tag {
+			return true
+		}
+	}
+	return false
+}
+
 // GetActiveEnvironmentVariables returns all enabled variables for the active environment as a map
-func (d *Database) GetActiveEnvironmentVariables() (map[string]string, error) {
+func (d *sqliteStore) GetActiveEnvironmentVariables() (map[string]string, error) {
+	return d.activeEnvironmentVariables()
+}
+
+// GetActiveEnvironmentVariablesDecrypted is the entry point the request
+// runner's variable interpolation should read credentials through, kept
+// distinct by name from GetActiveEnvironmentVariables so a future UI-facing
+// summary of active variables can diverge (e.g. masking secrets) without
+// touching the runtime substitution path. Today both decrypt secrets the
+// same way.
+func (d *sqliteStore) GetActiveEnvironmentVariablesDecrypted() (map[string]string, error) {
+	return d.activeEnvironmentVariables()
+}
+
+// GetGlobalEnvironmentVariablesDecrypted returns all enabled variables for
+// the global (shared) environment, decrypted the same way
+// GetActiveEnvironmentVariablesDecrypted decrypts the active environment's.
+// The environment.Resolver merges this map underneath the active
+// environment's.
+func (d *sqliteStore) GetGlobalEnvironmentVariablesDecrypted() (map[string]string, error) {
+	return d.scopedEnvironmentVariables("is_global")
+}
+
+func (d *sqliteStore) activeEnvironmentVariables() (map[string]string, error) {
+	return d.scopedEnvironmentVariables("is_active")
+}
+
+// scopedEnvironmentVariables returns all enabled variables, decrypted, for
+// the single environment flagged by flagColumn (either "is_active" or
+// "is_global").
+func (d *sqliteStore) scopedEnvironmentVariables(flagColumn string) (map[string]string, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	rows, err := d.db.Query(fmt.Sprintf(`
+		SELECT ev.key, ev.value, ev.type
+		FROM environment_variables ev
+		JOIN environments e ON ev.environment_id = e.id
+		WHERE e.%s = 1 AND ev.enabled = 1
+	`, flagColumn))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s variables: %w", flagColumn, err)
+	}
+	defer rows.Close()
+
+	vars := make(map[string]string)
+	for rows.Next() {
+		var key, value, varType string
+		if err := rows.Scan(&key, &value, &varType); err != nil {
+			continue
+		}
+		if varType == VariableTypeSecret && d.secretsMgr != nil {
+			if plaintext, err := d.secretsMgr.Decrypt(value); err == nil {
+				value = plaintext
+			}
+		}
+		vars[key] = value
+	}
+
+	return vars, nil
+}
+
+// GetActiveEnvironmentVariablesByTags is a variant of
+// GetActiveEnvironmentVariables that only returns variables whose Tags
+// include at least one of tags. An empty tags slice matches nothing,
+// rather than silently falling back to "every variable".
+func (d *sqliteStore) GetActiveEnvironmentVariablesByTags(tags []string) (map[string]string, error) {
+	if len(tags) == 0 {
+		return map[string]string{}, nil
+	}
+
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
 	rows, err := d.db.Query(`
-		SELECT ev.key, ev.value
+		SELECT ev.key, ev.value, ev.type, ev.tags
 		FROM environment_variables ev
 		JOIN environments e ON ev.environment_id = e.id
 		WHERE e.is_active = 1 AND ev.enabled = 1
@@ -948,34 +2031,314 @@ func (d *Database) GetActiveEnvironmentVariables() (map[string]string, error) {
 
 	vars := make(map[string]string)
 	for rows.Next() {
-		var key, value string
-		if err := rows.Scan(&key, &value); err != nil {
+		var key, value, varType, varTags string
+		if err := rows.Scan(&key, &value, &varType, &varTags); err != nil {
 			continue
 		}
+		if !hasAnyTag(varTags, tags) {
+			continue
+		}
+		if varType == VariableTypeSecret && d.secretsMgr != nil {
+			if plaintext, err := d.secretsMgr.Decrypt(value); err == nil {
+				value = plaintext
+			}
+		}
 		vars[key] = value
 	}
 
 	return vars, nil
 }
 
+// hasAnyTag reports whether tagsCSV's comma-separated list shares any tag
+// with want.
+func hasAnyTag(tagsCSV string, want []string) bool {
+	for _, tag := range want {
+		if hasTag(tagsCSV, tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // DeleteEnvironmentVariable removes a variable
-func (d *Database) DeleteEnvironmentVariable(id string) error {
+func (d *sqliteStore) DeleteEnvironmentVariable(id string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
+	var environmentID string
+	if err := d.db.QueryRow("SELECT environment_id FROM environment_variables WHERE id = ?", id).Scan(&environmentID); err != nil {
+		return fmt.Errorf("failed to look up variable's environment: %w", err)
+	}
+
 	_, err := d.db.Exec("DELETE FROM environment_variables WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("failed to delete variable: %w", err)
 	}
+
+	return d.snapshotEnvironment(environmentID)
+}
+
+// SetVariableType migrates a variable to varType (VariableTypeString or
+// VariableTypeSecret), re-writing its stored value between plaintext and
+// encrypted accordingly.
+func (d *sqliteStore) SetVariableType(id, varType string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var value, currentType string
+	err := d.db.QueryRow("SELECT value, type FROM environment_variables WHERE id = ?", id).Scan(&value, &currentType)
+	if err != nil {
+		return fmt.Errorf("failed to load variable: %w", err)
+	}
+
+	if currentType == varType {
+		return nil
+	}
+
+	var newValue string
+	if currentType == VariableTypeSecret {
+		// Moving from encrypted to plaintext: decrypt first.
+		if d.secretsMgr == nil {
+			return fmt.Errorf("cannot decrypt variable: encryption manager is unavailable")
+		}
+		newValue, err = d.secretsMgr.Decrypt(value)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt variable: %w", err)
+		}
+	} else {
+		newValue, err = d.sealIfSecret(value, varType)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = d.db.Exec(
+		"UPDATE environment_variables SET value = ?, type = ?, updated_at = ? WHERE id = ?",
+		newValue, varType, time.Now().Unix(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update variable: %w", err)
+	}
+	return nil
+}
+
+// RotateSecretsKey generates a fresh data key and re-encrypts every secret
+// environment variable under it, so a compromised (or merely old) key can
+// be retired without losing access to existing secrets.
+func (d *sqliteStore) RotateSecretsKey() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.secretsMgr == nil {
+		return fmt.Errorf("cannot rotate secrets key: encryption manager is unavailable")
+	}
+
+	oldKey, err := d.secretsMgr.RotateKey()
+	if err != nil {
+		return fmt.Errorf("failed to rotate secrets key: %w", err)
+	}
+
+	rows, err := d.db.Query("SELECT id, value FROM environment_variables WHERE type = ?", VariableTypeSecret)
+	if err != nil {
+		return fmt.Errorf("failed to query secret variables: %w", err)
+	}
+
+	type secretRow struct {
+		id    string
+		value string
+	}
+	var secretRows []secretRow
+	for rows.Next() {
+		var r secretRow
+		if err := rows.Scan(&r.id, &r.value); err != nil {
+			continue
+		}
+		secretRows = append(secretRows, r)
+	}
+	rows.Close()
+
+	for _, r := range secretRows {
+		reencrypted, err := d.secretsMgr.ReencryptWithOldKey(oldKey, r.value)
+		if err != nil {
+			return fmt.Errorf("failed to re-encrypt variable %s: %w", r.id, err)
+		}
+		if _, err := d.db.Exec("UPDATE environment_variables SET value = ? WHERE id = ?", reencrypted, r.id); err != nil {
+			return fmt.Errorf("failed to persist rotated variable %s: %w", r.id, err)
+		}
+	}
+
 	return nil
 }
 
+// ListEnvironmentRevisions returns environmentID's revision history, newest
+// first, without their variable snapshots (use GetEnvironmentRevision for
+// the full payload of a single revision).
+func (d *sqliteStore) ListEnvironmentRevisions(environmentID string) ([]EnvironmentRevision, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	rows, err := d.db.Query(
+		"SELECT id, environment_id, revision, author, created_at FROM environment_revisions WHERE environment_id = ? ORDER BY revision DESC",
+		environmentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query environment revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]EnvironmentRevision, 0)
+	for rows.Next() {
+		var r EnvironmentRevision
+		if err := rows.Scan(&r.ID, &r.EnvironmentID, &r.Revision, &r.Author, &r.CreatedAt); err != nil {
+			continue
+		}
+		revisions = append(revisions, r)
+	}
+
+	return revisions, nil
+}
+
+// GetEnvironmentRevision returns a single revision of environmentID,
+// including the full variable snapshot taken at that revision.
+func (d *sqliteStore) GetEnvironmentRevision(environmentID string, revision int) (*EnvironmentRevision, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.environmentRevisionLocked(environmentID, revision)
+}
+
+// environmentRevisionLocked is GetEnvironmentRevision's query logic without
+// the RLock, for callers (DiffEnvironmentRevisions, RollbackEnvironment)
+// that already hold d.mu.
+func (d *sqliteStore) environmentRevisionLocked(environmentID string, revision int) (*EnvironmentRevision, error) {
+	var r EnvironmentRevision
+	var snapshotJSON string
+	err := d.db.QueryRow(
+		"SELECT id, environment_id, revision, author, snapshot_json, created_at FROM environment_revisions WHERE environment_id = ? AND revision = ?",
+		environmentID, revision,
+	).Scan(&r.ID, &r.EnvironmentID, &r.Revision, &r.Author, &snapshotJSON, &r.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load environment revision %d: %w", revision, err)
+	}
+
+	if err := json.Unmarshal([]byte(snapshotJSON), &r.Variables); err != nil {
+		return nil, fmt.Errorf("failed to decode environment revision snapshot: %w", err)
+	}
+
+	return &r, nil
+}
+
+// revisionPlainValue returns v.Value in comparable plaintext form. Snapshot
+// values are sealed the same as the environment_variables table (see
+// environmentVariablesRawLocked), so secret values must be decrypted before
+// comparing two revisions — GCM reseals the same plaintext to different
+// ciphertext every time, so comparing sealed values directly would report
+// every secret as Changed regardless of whether it actually changed.
+func (d *sqliteStore) revisionPlainValue(v EnvironmentVariable) string {
+	if v.IsSecret() && d.secretsMgr != nil {
+		if plaintext, err := d.secretsMgr.Decrypt(v.Value); err == nil {
+			return plaintext
+		}
+	}
+	return v.Value
+}
+
+// DiffEnvironmentRevisions compares revisions a and b of environmentID,
+// keyed by variable Key, and reports which keys were added, removed, or
+// changed value going from a to b.
+func (d *sqliteStore) DiffEnvironmentRevisions(environmentID string, a, b int) (*EnvironmentDiff, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	revA, err := d.environmentRevisionLocked(environmentID, a)
+	if err != nil {
+		return nil, err
+	}
+	revB, err := d.environmentRevisionLocked(environmentID, b)
+	if err != nil {
+		return nil, err
+	}
+
+	byKeyA := make(map[string]EnvironmentVariable, len(revA.Variables))
+	for _, v := range revA.Variables {
+		byKeyA[v.Key] = v
+	}
+
+	diff := &EnvironmentDiff{}
+	seen := make(map[string]bool, len(revB.Variables))
+	for _, v := range revB.Variables {
+		seen[v.Key] = true
+		old, ok := byKeyA[v.Key]
+		if !ok {
+			diff.Added = append(diff.Added, v.Key)
+			continue
+		}
+		if d.revisionPlainValue(old) != d.revisionPlainValue(v) {
+			diff.Changed = append(diff.Changed, v.Key)
+		}
+	}
+	for _, v := range revA.Variables {
+		if !seen[v.Key] {
+			diff.Removed = append(diff.Removed, v.Key)
+		}
+	}
+
+	return diff, nil
+}
+
+// RollbackEnvironment atomically rewrites environmentID's current variables
+// to match the snapshot recorded at revision, then records the rollback
+// itself as a new revision (so rolling back is itself auditable and can be
+// undone by rolling forward again). Snapshot values are already sealed the
+// same as the environment_variables table (see environmentVariablesRawLocked),
+// so they're inserted as-is rather than run back through sealIfSecret, which
+// would double-encrypt them.
+func (d *sqliteStore) RollbackEnvironment(environmentID string, revision int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	target, err := d.environmentRevisionLocked(environmentID, revision)
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM environment_variables WHERE environment_id = ?", environmentID); err != nil {
+		return fmt.Errorf("failed to clear current variables: %w", err)
+	}
+
+	now := time.Now().Unix()
+	for _, v := range target.Variables {
+		_, err = tx.Exec(
+			"INSERT INTO environment_variables (id, environment_id, key, value, enabled, type, tags, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			uuid.New().String(), environmentID, v.Key, v.Value, boolToInt(v.Enabled), v.Type, v.Tags, now, now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to restore variable %s: %w", v.Key, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return d.snapshotEnvironment(environmentID)
+}
+
 // ============================================================================
 // Environment Export/Import Methods
 // ============================================================================
 
-// ExportEnvironment exports an environment with all its variables
-func (d *Database) ExportEnvironment(id string) (*EnvironmentExport, error) {
+// ExportEnvironment exports an environment with all its variables. Secret
+// variables have their value replaced with secretPlaceholder so the export
+// can be written to disk or shared without leaking decrypted secrets;
+// ImportEnvironment skips a secret that still carries the placeholder.
+func (d *sqliteStore) ExportEnvironment(id string) (*EnvironmentExport, error) {
 	env, err := d.GetEnvironment(id)
 	if err != nil {
 		return nil, err
@@ -986,14 +2349,25 @@ func (d *Database) ExportEnvironment(id string) (*EnvironmentExport, error) {
 		return nil, err
 	}
 
+	for i := range vars {
+		if vars[i].IsSecret() {
+			vars[i].Value = secretPlaceholder
+		}
+	}
+
 	return &EnvironmentExport{
 		Name:      env.Name,
 		Variables: vars,
 	}, nil
 }
 
-// ImportEnvironment imports an environment from export data
-func (d *Database) ImportEnvironment(data *EnvironmentExport) (string, error) {
+// ImportEnvironment imports an environment from export data. A secret
+// variable whose value is still secretPlaceholder (i.e. it came from an
+// ExportEnvironment output rather than a real secret value) cannot be
+// resolved to cleartext here, since ExportEnvironment never included it in
+// the first place — that variable is skipped rather than storing the
+// placeholder text as if it were the real secret.
+func (d *sqliteStore) ImportEnvironment(data *EnvironmentExport) (string, error) {
 	// Create the environment
 	envID, err := d.CreateEnvironment(data.Name)
 	if err != nil {
@@ -1002,7 +2376,13 @@ func (d *Database) ImportEnvironment(data *EnvironmentExport) (string, error) {
 
 	// Import all variables
 	for _, v := range data.Variables {
-		_, err := d.SetEnvironmentVariable(envID, v.Key, v.Value, v.Enabled)
+		if v.IsSecret() && v.Value == secretPlaceholder {
+			// No real secret value to import; skip rather than persist the
+			// placeholder text as a fake secret.
+			continue
+		}
+
+		_, err := d.SetEnvironmentVariable(envID, v.Key, v.Value, v.Type, v.Enabled)
 		if err != nil {
 			// Continue even if one variable fails
 			continue
@@ -0,0 +1,573 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ============================================================================
+// Postman Collection v2.1 Import/Export
+// ============================================================================
+
+// postmanCollection models the subset of Postman Collection Format v2.1 that
+// volt-api understands: info/item tree, plus collection-level variables.
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema,omitempty"`
+	} `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanVariable `json:"variable,omitempty"`
+}
+
+// postmanItem is either a folder (Item populated, Request nil) or a request.
+type postmanItem struct {
+	Name    string          `json:"name"`
+	Item    []postmanItem   `json:"item,omitempty"`
+	Request *postmanRequest `json:"request,omitempty"`
+}
+
+type postmanRequest struct {
+	Method string          `json:"method"`
+	URL    postmanURL      `json:"url"`
+	Header []postmanHeader `json:"header,omitempty"`
+	Body   *postmanBody    `json:"body,omitempty"`
+}
+
+// postmanURL accepts Postman's two url shapes: a bare string, or an object
+// with a "raw" field (plus host/path/query breakdowns we don't need).
+type postmanURL struct {
+	Raw string
+}
+
+func (u *postmanURL) UnmarshalJSON(b []byte) error {
+	var raw string
+	if err := json.Unmarshal(b, &raw); err == nil {
+		u.Raw = raw
+		return nil
+	}
+	var obj struct {
+		Raw string `json:"raw"`
+	}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+	u.Raw = obj.Raw
+	return nil
+}
+
+func (u postmanURL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.Raw)
+}
+
+type postmanHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type postmanBody struct {
+	Mode       string      `json:"mode,omitempty"`
+	Raw        string      `json:"raw,omitempty"`
+	URLEncoded []postmanKV `json:"urlencoded,omitempty"`
+	FormData   []postmanKV `json:"formdata,omitempty"`
+}
+
+type postmanKV struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type postmanVariable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ImportPostmanCollection imports a Postman Collection v2.1 export, creating
+// a new Collection plus (if the export carries collection-level variables) a
+// matching Environment. Postman folders become real, nested Folder rows
+// rather than being flattened into request names.
+func (d *sqliteStore) ImportPostmanCollection(data []byte) (string, error) {
+	var coll postmanCollection
+	if err := json.Unmarshal(data, &coll); err != nil {
+		return "", fmt.Errorf("failed to parse Postman collection: %w", err)
+	}
+
+	name := coll.Info.Name
+	if name == "" {
+		name = "Imported Collection"
+	}
+
+	collectionID, err := d.CreateCollection(name)
+	if err != nil {
+		return "", err
+	}
+
+	for _, item := range coll.Item {
+		d.importPostmanItem(collectionID, "", item)
+	}
+
+	if len(coll.Variable) > 0 {
+		envID, err := d.CreateEnvironment(name)
+		if err == nil {
+			for _, v := range coll.Variable {
+				d.SetEnvironmentVariable(envID, v.Key, v.Value, VariableTypeString, true)
+			}
+		}
+	}
+
+	return collectionID, nil
+}
+
+// importPostmanItem walks one node of the Postman item tree. A folder node
+// (Request nil) becomes a Folder row its children are created under;
+// a request node is saved and, if folderID is set, filed into that folder.
+func (d *sqliteStore) importPostmanItem(collectionID, folderID string, item postmanItem) {
+	if item.Request == nil {
+		childFolderID, err := d.CreateFolder(collectionID, folderID, item.Name)
+		if err != nil {
+			return
+		}
+		for _, child := range item.Item {
+			d.importPostmanItem(collectionID, childFolderID, child)
+		}
+		return
+	}
+
+	method := strings.ToUpper(item.Request.Method)
+	if method == "" {
+		method = "GET"
+	}
+
+	headers := make(map[string]string, len(item.Request.Header))
+	for _, h := range item.Request.Header {
+		headers[h.Key] = translatePostmanTemplate(h.Value)
+	}
+
+	body := ""
+	if item.Request.Body != nil {
+		switch item.Request.Body.Mode {
+		case "raw":
+			body = item.Request.Body.Raw
+		case "urlencoded":
+			body = encodePostmanKVs(item.Request.Body.URLEncoded)
+		case "formdata":
+			body = encodePostmanKVs(item.Request.Body.FormData)
+		}
+		body = translatePostmanTemplate(body)
+	}
+
+	reqURL := translatePostmanTemplate(item.Request.URL.Raw)
+	reqID, err := d.SaveRequestToCollection(collectionID, item.Name, method, reqURL, headers, body)
+	if err == nil && folderID != "" {
+		d.MoveSavedRequestToFolder(reqID, folderID)
+	}
+}
+
+// encodePostmanKVs renders urlencoded/formdata entries as a single
+// application/x-www-form-urlencoded body string.
+func encodePostmanKVs(kvs []postmanKV) string {
+	values := url.Values{}
+	for _, kv := range kvs {
+		values.Add(kv.Key, kv.Value)
+	}
+	return values.Encode()
+}
+
+// translatePostmanTemplate maps Postman's {{var}} template syntax onto
+// volt-api's own environment variable placeholders. The two use the same
+// {{var}} convention, so this is currently a pass-through kept as a named
+// step so a future syntax change only has one place to update.
+func translatePostmanTemplate(s string) string {
+	return s
+}
+
+// ExportPostmanCollection exports a collection as a Postman Collection v2.1
+// document, preserving its nested folder tree as Postman folder items.
+func (d *sqliteStore) ExportPostmanCollection(id string) ([]byte, error) {
+	collection, err := d.GetCollection(id)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := d.GetFolderTree(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var coll postmanCollection
+	coll.Info.Name = collection.Name
+	coll.Info.Schema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+	for _, req := range tree.Requests {
+		coll.Item = append(coll.Item, savedRequestToPostmanItem(req))
+	}
+	for _, child := range tree.Children {
+		coll.Item = append(coll.Item, folderNodeToPostmanItem(child))
+	}
+
+	return json.MarshalIndent(coll, "", "  ")
+}
+
+// savedRequestToPostmanItem converts one SavedRequest into a Postman
+// request item.
+func savedRequestToPostmanItem(req SavedRequest) postmanItem {
+	headers := make([]postmanHeader, 0, len(req.Headers))
+	for k, v := range req.Headers {
+		headers = append(headers, postmanHeader{Key: k, Value: v})
+	}
+
+	return postmanItem{
+		Name: req.Name,
+		Request: &postmanRequest{
+			Method: req.Method,
+			URL:    postmanURL{Raw: req.URL},
+			Header: headers,
+			Body:   &postmanBody{Mode: "raw", Raw: req.Body},
+		},
+	}
+}
+
+// folderNodeToPostmanItem recursively converts an assembled FolderNode into
+// a Postman folder item containing its requests and subfolders.
+func folderNodeToPostmanItem(node *FolderNode) postmanItem {
+	item := postmanItem{Name: node.Name}
+	for _, req := range node.Requests {
+		item.Item = append(item.Item, savedRequestToPostmanItem(req))
+	}
+	for _, child := range node.Children {
+		item.Item = append(item.Item, folderNodeToPostmanItem(child))
+	}
+	return item
+}
+
+// ============================================================================
+// OpenAPI Import
+// ============================================================================
+
+// openapiHTTPMethods lists the Paths Item Object keys that represent actual
+// operations, as opposed to sibling keys like "parameters" or "$ref".
+var openapiHTTPMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+type openapiDoc struct {
+	Info struct {
+		Title string `json:"title"`
+	} `json:"info"`
+	Servers []openapiServer                        `json:"servers"`
+	Paths   map[string]map[string]openapiOperation `json:"paths"`
+}
+
+type openapiServer struct {
+	URL       string                         `json:"url"`
+	Variables map[string]openapiServerVarDef `json:"variables"`
+}
+
+type openapiServerVarDef struct {
+	Default string `json:"default"`
+}
+
+type openapiOperation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Parameters  []openapiParameter  `json:"parameters"`
+	RequestBody *openapiRequestBody `json:"requestBody"`
+}
+
+type openapiParameter struct {
+	Name   string `json:"name"`
+	In     string `json:"in"`
+	Schema struct {
+		Default interface{} `json:"default"`
+	} `json:"schema"`
+}
+
+type openapiRequestBody struct {
+	Content map[string]openapiMediaType `json:"content"`
+}
+
+type openapiMediaType struct {
+	Schema json.RawMessage `json:"schema"`
+}
+
+// jsonSchema is the subset of JSON Schema (as used by OpenAPI requestBody
+// schemas) that generateExample walks to synthesize a plausible example.
+type jsonSchema struct {
+	Type       string                     `json:"type"`
+	Example    interface{}                `json:"example"`
+	Default    interface{}                `json:"default"`
+	Enum       []interface{}              `json:"enum"`
+	Properties map[string]json.RawMessage `json:"properties"`
+	Items      json.RawMessage            `json:"items"`
+}
+
+// generateExample synthesizes a value for a JSON Schema fragment, preferring
+// an explicit example, then a default, then the first enum value, then a
+// type-appropriate zero value. Objects and arrays recurse into their
+// properties/items.
+func generateExample(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	var s jsonSchema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil
+	}
+	if s.Example != nil {
+		return s.Example
+	}
+	if s.Default != nil {
+		return s.Default
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[0]
+	}
+
+	switch s.Type {
+	case "object":
+		obj := make(map[string]interface{}, len(s.Properties))
+		for key, propRaw := range s.Properties {
+			obj[key] = generateExample(propRaw)
+		}
+		return obj
+	case "array":
+		if len(s.Items) > 0 {
+			return []interface{}{generateExample(s.Items)}
+		}
+		return []interface{}{}
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "string":
+		return ""
+	default:
+		return nil
+	}
+}
+
+// ImportOpenAPI imports an OpenAPI 3.x document, creating one SavedRequest
+// per operation in a new collection, each with the document's first server
+// URL as its literal base. That URL (as the "server" variable), its server
+// variables' defaults, and any "server"-scoped parameters are also stored
+// into envID; the saved request URLs do not reference {{server}}.
+func (d *sqliteStore) ImportOpenAPI(data []byte, envID string) (string, error) {
+	var doc openapiDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	name := doc.Info.Title
+	if name == "" {
+		name = "Imported API"
+	}
+
+	collectionID, err := d.CreateCollection(name)
+	if err != nil {
+		return "", err
+	}
+
+	var baseURL string
+	if len(doc.Servers) > 0 {
+		baseURL = doc.Servers[0].URL
+	}
+
+	for path, operations := range doc.Paths {
+		for method, op := range operations {
+			if !openapiHTTPMethods[strings.ToLower(method)] {
+				continue
+			}
+
+			reqName := op.OperationID
+			if reqName == "" {
+				reqName = op.Summary
+			}
+			if reqName == "" {
+				reqName = strings.ToUpper(method) + " " + path
+			}
+
+			body := ""
+			if op.RequestBody != nil {
+				if media, ok := op.RequestBody.Content["application/json"]; ok {
+					if example := generateExample(media.Schema); example != nil {
+						if encoded, err := json.MarshalIndent(example, "", "  "); err == nil {
+							body = string(encoded)
+						}
+					}
+				}
+			}
+
+			d.SaveRequestToCollection(collectionID, reqName, strings.ToUpper(method), baseURL+path, map[string]string{}, body)
+
+			if envID != "" {
+				for _, param := range op.Parameters {
+					if param.In != "server" {
+						continue
+					}
+					d.SetEnvironmentVariable(envID, param.Name, fmt.Sprintf("%v", param.Schema.Default), VariableTypeString, true)
+				}
+			}
+		}
+	}
+
+	if envID != "" && baseURL != "" {
+		d.SetEnvironmentVariable(envID, "server", baseURL, VariableTypeString, true)
+		if len(doc.Servers) > 0 {
+			for key, v := range doc.Servers[0].Variables {
+				d.SetEnvironmentVariable(envID, key, v.Default, VariableTypeString, true)
+			}
+		}
+	}
+
+	return collectionID, nil
+}
+
+// ============================================================================
+// Insomnia v4 Import/Export
+// ============================================================================
+
+// insomniaExport models the flat resources array of an Insomnia v4 export.
+// Folders ("request_group") and requests both reference their parent via
+// ParentID, mirroring Postman's nested item tree but without the nesting.
+type insomniaExport struct {
+	ExportFormat int                `json:"__export_format"`
+	Resources    []insomniaResource `json:"resources"`
+}
+
+type insomniaResource struct {
+	ID       string           `json:"_id"`
+	Type     string           `json:"_type"`
+	ParentID string           `json:"parentId"`
+	Name     string           `json:"name"`
+	Method   string           `json:"method,omitempty"`
+	URL      string           `json:"url,omitempty"`
+	Headers  []insomniaHeader `json:"headers,omitempty"`
+	Body     *insomniaBody    `json:"body,omitempty"`
+}
+
+type insomniaHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type insomniaBody struct {
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+}
+
+// ImportInsomniaCollection imports an Insomnia v4 export. Unlike
+// ImportPostmanCollection it creates no Folder rows: a request_group chain
+// becomes a "parent/child" name prefix on the request it contains.
+func (d *sqliteStore) ImportInsomniaCollection(data []byte) (string, error) {
+	var export insomniaExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return "", fmt.Errorf("failed to parse Insomnia export: %w", err)
+	}
+
+	byID := make(map[string]insomniaResource, len(export.Resources))
+	children := make(map[string][]insomniaResource)
+	var workspace *insomniaResource
+
+	for _, res := range export.Resources {
+		byID[res.ID] = res
+		children[res.ParentID] = append(children[res.ParentID], res)
+		if res.Type == "workspace" && workspace == nil {
+			w := res
+			workspace = &w
+		}
+	}
+
+	name := "Imported Collection"
+	if workspace != nil {
+		if workspace.Name != "" {
+			name = workspace.Name
+		}
+	} else {
+		workspace = &insomniaResource{ID: ""}
+	}
+
+	collectionID, err := d.CreateCollection(name)
+	if err != nil {
+		return "", err
+	}
+
+	var walk func(parentID, prefix string)
+	walk = func(parentID, prefix string) {
+		for _, res := range children[parentID] {
+			switch res.Type {
+			case "request_group":
+				childPrefix := res.Name
+				if prefix != "" {
+					childPrefix = prefix + "/" + res.Name
+				}
+				walk(res.ID, childPrefix)
+			case "request":
+				reqName := res.Name
+				if prefix != "" {
+					reqName = prefix + "/" + res.Name
+				}
+				headers := make(map[string]string, len(res.Headers))
+				for _, h := range res.Headers {
+					headers[h.Name] = h.Value
+				}
+				body := ""
+				if res.Body != nil {
+					body = res.Body.Text
+				}
+				method := strings.ToUpper(res.Method)
+				if method == "" {
+					method = "GET"
+				}
+				d.SaveRequestToCollection(collectionID, reqName, method, res.URL, headers, body)
+			}
+		}
+	}
+	walk(workspace.ID, "")
+
+	return collectionID, nil
+}
+
+// ExportInsomniaCollection exports a collection as an Insomnia v4 export: a
+// workspace resource plus one request resource per saved request. Folders
+// are not exported: every request sits directly under the workspace.
+func (d *sqliteStore) ExportInsomniaCollection(id string) ([]byte, error) {
+	export, err := d.ExportCollection(id)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := d.GetCollectionRequests(id)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaceID := "wrk_" + id
+	out := insomniaExport{
+		ExportFormat: 4,
+		Resources: []insomniaResource{
+			{ID: workspaceID, Type: "workspace", Name: export.Name},
+		},
+	}
+
+	for _, req := range requests {
+		headers := make([]insomniaHeader, 0, len(req.Headers))
+		for k, v := range req.Headers {
+			headers = append(headers, insomniaHeader{Name: k, Value: v})
+		}
+
+		out.Resources = append(out.Resources, insomniaResource{
+			ID:       "req_" + req.ID,
+			Type:     "request",
+			ParentID: workspaceID,
+			Name:     req.Name,
+			Method:   req.Method,
+			URL:      req.URL,
+			Headers:  headers,
+			Body:     &insomniaBody{MimeType: "application/json", Text: req.Body},
+		})
+	}
+
+	return json.MarshalIndent(out, "", "  ")
+}
@@ -0,0 +1,230 @@
+// Package migrations applies volt-api's SQLite schema as a sequence of
+// numbered, embedded SQL files instead of one monolithic CREATE TABLE
+// blob, so future schema changes (new columns, new tables) ship as their
+// own migration rather than silently no-op'ing on existing installs.
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migration pairs the up/down SQL embedded under a single numeric prefix,
+// e.g. "0001_init.up.sql" and "0001_init.down.sql".
+type migration struct {
+	version int
+	name    string
+	upSQL   string
+	downSQL string
+}
+
+// load reads every embedded migration file and pairs them up by version,
+// sorted ascending.
+func load() ([]migration, error) {
+	entries, err := migrationFS.ReadDir(".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		version, name, ok := parseFilename(name)
+		if !ok {
+			continue
+		}
+
+		content, err := migrationFS.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &migration{version: version}
+			byVersion[version] = m
+		}
+
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			m.name = strings.TrimSuffix(strings.TrimPrefix(name, strconv.Itoa(version)+"_"), ".up.sql")
+			m.upSQL = string(content)
+		case strings.HasSuffix(name, ".down.sql"):
+			m.downSQL = string(content)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+
+	return migrations, nil
+}
+
+// parseFilename extracts the leading version number from a migration
+// filename, e.g. "0002_add_tags.up.sql" -> (2, "0002_add_tags.up.sql", true).
+func parseFilename(name string) (int, string, bool) {
+	underscore := strings.IndexByte(name, '_')
+	if underscore < 0 {
+		return 0, "", false
+	}
+	version, err := strconv.Atoi(name[:underscore])
+	if err != nil {
+		return 0, "", false
+	}
+	return version, name, true
+}
+
+const ensureVersionTableSQL = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version INTEGER PRIMARY KEY,
+	applied_at INTEGER NOT NULL
+);
+`
+
+// currentVersion returns the highest applied migration version, or 0 if the
+// database has never been migrated.
+func currentVersion(db *sql.DB) (int, error) {
+	var version sql.NullInt64
+	if err := db.QueryRow("SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+// Migrate brings db up to the latest version this binary knows about,
+// applying each pending "up" script inside its own transaction and
+// recording its version in schema_migrations. It refuses to start if the
+// database is already at a version newer than this binary understands,
+// rather than silently skipping whatever schema changes a newer binary
+// made.
+func Migrate(db *sql.DB) error {
+	if _, err := db.Exec(ensureVersionTableSQL); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	migrations, err := load()
+	if err != nil {
+		return err
+	}
+
+	current, err := currentVersion(db)
+	if err != nil {
+		return fmt.Errorf("failed to read current schema version: %w", err)
+	}
+
+	latest := 0
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].version
+	}
+	if current > latest {
+		return fmt.Errorf("database schema version %d is newer than this binary supports (latest known: %d)", current, latest)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyUp(db, m); err != nil {
+			if !optionalFeatureUnavailable(err) {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", m.version, m.name, err)
+			}
+			fmt.Printf("Warning: migration %d_%s needs a SQLite feature this build doesn't have, skipping: %v\n", m.version, m.name, err)
+			if err := recordVersion(db, m.version); err != nil {
+				return fmt.Errorf("failed to record skipped migration %d_%s: %w", m.version, m.name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// optionalFeatureUnavailable reports whether err indicates a migration
+// statement depended on a SQLite compile-time extension (currently just
+// FTS5) that this binary's sqlite build doesn't include, rather than a
+// genuine schema bug. Such migrations are recorded as applied so every
+// future startup doesn't retry and fail identically; the feature that
+// depended on them (full-text search) degrades to a plainer query instead.
+func optionalFeatureUnavailable(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "fts5") || strings.Contains(msg, "no such module")
+}
+
+// recordVersion marks version as applied without running its up script, for
+// migrations skipped by optionalFeatureUnavailable.
+func recordVersion(db *sql.DB, version int) error {
+	_, err := db.Exec("INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)", version, time.Now().Unix())
+	return err
+}
+
+func applyUp(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.upSQL); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)", m.version, time.Now().Unix()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MigrateDown reverts every applied migration with a version greater than
+// target, newest first, running each one's "down" script. It exists for
+// tests and for the CLI's migrate-down hook; normal app startup never calls
+// it.
+func MigrateDown(db *sql.DB, target int) error {
+	migrations, err := load()
+	if err != nil {
+		return err
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version > migrations[j].version })
+
+	current, err := currentVersion(db)
+	if err != nil {
+		return fmt.Errorf("failed to read current schema version: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= target || m.version > current {
+			continue
+		}
+		if err := applyDown(db, m); err != nil {
+			return fmt.Errorf("failed to revert migration %d_%s: %w", m.version, m.name, err)
+		}
+	}
+
+	return nil
+}
+
+func applyDown(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if strings.TrimSpace(m.downSQL) != "" {
+		if _, err := tx.Exec(m.downSQL); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM schema_migrations WHERE version = ?", m.version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
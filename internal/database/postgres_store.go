@@ -0,0 +1,1615 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"volt-api/internal/secrets"
+)
+
+// postgresStore is the Postgres-backed Store implementation, for team/server
+// deployments that want a shared database instead of each device's own
+// SQLite file. It satisfies the same Store interface as sqliteStore by running
+// the same dynamic filter-building logic (appendHistoryFilters) through
+// rebind, and SQL text that was hand-translated from the SQLite schema
+// rather than shared migration files — see postgresSchema below.
+//
+// Known gaps versus the SQLite store, left as explicit follow-up work:
+//   - SearchHistory falls back to a plain ILIKE match; there's no Postgres
+//     equivalent of the FTS5 path yet (tsvector/tsquery would be the natural
+//     fit, but that's its own migration and ranking design).
+//   - Schema bootstrap is a flat CREATE TABLE IF NOT EXISTS script run once
+//     at Open time, not a versioned migration set like internal/database/
+//     migrations; this is fine for a first backend but means schema changes
+//     here won't get the same safe forward/backward migration story SQLite
+//     has until someone builds one.
+//   - changelog rows are only written by the SQLite store today (see
+//     sqliteStore.logChange); postgresStore doesn't populate them yet, so a
+//     a replicator pointed at a Postgres backend currently has nothing to
+//     tail.
+type postgresStore struct {
+	pool *pgxpool.Pool
+
+	secretsMgr *secrets.Manager
+
+	// originID identifies this machine's postgresStore instance for
+	// callers (e.g. sync) that need a stable per-device ID even though the
+	// backend itself is shared; it's the same file-persisted ID the
+	// SQLite-backed sqliteStore mints via loadOrCreateOriginID.
+	originID string
+
+	secretBodyPatternsMu sync.RWMutex
+	secretBodyPatterns   []*regexp.Regexp
+}
+
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS history (
+	id TEXT PRIMARY KEY,
+	method TEXT NOT NULL,
+	url TEXT NOT NULL,
+	headers TEXT,
+	body TEXT,
+	response_body TEXT,
+	status_code INTEGER,
+	timing_ms BIGINT,
+	created_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_history_created_at ON history(created_at DESC);
+
+CREATE TABLE IF NOT EXISTS collections (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS folders (
+	id TEXT PRIMARY KEY,
+	collection_id TEXT NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+	parent_id TEXT REFERENCES folders(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	path TEXT NOT NULL,
+	position INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_folders_collection ON folders(collection_id);
+CREATE INDEX IF NOT EXISTS idx_folders_path ON folders(path);
+
+CREATE TABLE IF NOT EXISTS saved_requests (
+	id TEXT PRIMARY KEY,
+	collection_id TEXT NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+	folder_id TEXT REFERENCES folders(id) ON DELETE SET NULL,
+	name TEXT NOT NULL,
+	method TEXT NOT NULL,
+	url TEXT NOT NULL,
+	headers TEXT,
+	body TEXT,
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_saved_requests_collection ON saved_requests(collection_id);
+CREATE INDEX IF NOT EXISTS idx_saved_requests_folder ON saved_requests(folder_id);
+
+CREATE TABLE IF NOT EXISTS environments (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE,
+	is_active BOOLEAN NOT NULL DEFAULT FALSE,
+	is_global BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS environment_variables (
+	id TEXT PRIMARY KEY,
+	environment_id TEXT NOT NULL REFERENCES environments(id) ON DELETE CASCADE,
+	key TEXT NOT NULL,
+	value TEXT NOT NULL,
+	enabled BOOLEAN NOT NULL DEFAULT TRUE,
+	type TEXT NOT NULL DEFAULT 'string',
+	tags TEXT NOT NULL DEFAULT '',
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL,
+	UNIQUE(environment_id, key)
+);
+CREATE INDEX IF NOT EXISTS idx_env_vars_environment ON environment_variables(environment_id);
+
+CREATE TABLE IF NOT EXISTS environment_revisions (
+	id TEXT PRIMARY KEY,
+	environment_id TEXT NOT NULL REFERENCES environments(id) ON DELETE CASCADE,
+	revision INTEGER NOT NULL,
+	author TEXT NOT NULL,
+	snapshot_json TEXT NOT NULL,
+	created_at BIGINT NOT NULL,
+	UNIQUE(environment_id, revision)
+);
+CREATE INDEX IF NOT EXISTS idx_env_revisions_environment ON environment_revisions(environment_id, revision);
+
+CREATE TABLE IF NOT EXISTS cookies (
+	id TEXT PRIMARY KEY,
+	environment_id TEXT NOT NULL,
+	domain TEXT NOT NULL,
+	path TEXT NOT NULL,
+	name TEXT NOT NULL,
+	value TEXT NOT NULL,
+	expires BIGINT NOT NULL DEFAULT 0,
+	secure BOOLEAN NOT NULL DEFAULT FALSE,
+	http_only BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL,
+	UNIQUE(environment_id, domain, path, name)
+);
+CREATE INDEX IF NOT EXISTS idx_cookies_environment ON cookies(environment_id);
+
+CREATE TABLE IF NOT EXISTS changelog (
+	id TEXT PRIMARY KEY,
+	entity TEXT NOT NULL,
+	op TEXT NOT NULL,
+	payload_json TEXT NOT NULL,
+	ts BIGINT NOT NULL,
+	origin_id TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_changelog_ts ON changelog(ts);
+
+CREATE TABLE IF NOT EXISTS webhook_configs (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	url TEXT NOT NULL,
+	auth_scheme TEXT NOT NULL DEFAULT '',
+	auth_token TEXT NOT NULL DEFAULT '',
+	signing_secret TEXT NOT NULL DEFAULT '',
+	event_filter TEXT NOT NULL DEFAULT 'all',
+	min_status_code INTEGER NOT NULL DEFAULT 0,
+	enabled BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_configs_enabled ON webhook_configs(enabled);
+`
+
+// newPostgresStore connects to dsn (a postgres:// or postgresql:// URL) and
+// bootstraps postgresSchema if needed.
+func newPostgresStore(dsn string) (*postgresStore, error) {
+	pool, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	if _, err := pool.Exec(context.Background(), postgresSchema); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to bootstrap postgres schema: %w", err)
+	}
+
+	secretsMgr, err := secrets.NewManager(nil)
+	if err != nil {
+		fmt.Printf("Warning: secret-at-rest encryption unavailable: %v\n", err)
+		secretsMgr = nil
+	}
+
+	originID, err := loadOrCreateOriginID()
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to load origin id: %w", err)
+	}
+
+	return &postgresStore{pool: pool, secretsMgr: secretsMgr, originID: originID}, nil
+}
+
+// DeviceID returns this machine's stable device identifier, for callers
+// (e.g. the app package's storage sync) that need a per-device ID even
+// against a shared backend like postgresStore.
+func (p *postgresStore) DeviceID() string {
+	return p.originID
+}
+
+func (p *postgresStore) Close() error {
+	p.pool.Close()
+	return nil
+}
+
+// exec runs query (written with "?" placeholders, rebound to Postgres "$N"
+// syntax) and discards the result, for INSERT/UPDATE/DELETE statements that
+// don't need RowsAffected.
+func (p *postgresStore) exec(query string, args ...interface{}) error {
+	_, err := p.pool.Exec(context.Background(), rebind(query), args...)
+	return err
+}
+
+func (p *postgresStore) SetSecretBodyPatterns(patterns []string) error {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pat := range patterns {
+		re, err := regexp.Compile(pat)
+		if err != nil {
+			return fmt.Errorf("invalid secret body pattern %q: %w", pat, err)
+		}
+		compiled = append(compiled, re)
+	}
+	p.secretBodyPatternsMu.Lock()
+	p.secretBodyPatterns = compiled
+	p.secretBodyPatternsMu.Unlock()
+	return nil
+}
+
+func (p *postgresStore) bodyHasSecretField(headers map[string]string, body string) bool {
+	if !isJSONContentType(headers) {
+		return false
+	}
+	p.secretBodyPatternsMu.RLock()
+	defer p.secretBodyPatternsMu.RUnlock()
+	for _, re := range p.secretBodyPatterns {
+		if re.MatchString(body) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *postgresStore) decryptHistoryBody(body string) string {
+	if p.secretsMgr == nil || !secrets.IsEncrypted(body) {
+		return body
+	}
+	if plaintext, err := p.secretsMgr.Decrypt(body); err == nil {
+		return plaintext
+	}
+	return body
+}
+
+// decodeHistoryBody reverses whatever SaveRequest did to a stored request
+// body: a secret-encrypted body is unsealed by decryptHistoryBody, and a
+// streamCompressBody'd one is restored by decodeStoredBody. A given body
+// only ever went through one of the two, but running both is harmless,
+// since each is a no-op on input it doesn't recognize.
+func (p *postgresStore) decodeHistoryBody(body string) string {
+	return decodeStoredBody(p.decryptHistoryBody(body))
+}
+
+// ============================================================================
+// History
+// ============================================================================
+
+// SaveRequest saves a request and response to history. If the request body
+// is JSON and contains a field matching a configured secret body pattern,
+// the request body is encrypted at rest the same way a secret environment
+// variable is; otherwise it, and the response body (which is never
+// secret-encrypted), are run through streamCompressBody, which only
+// touches bodies at least streamCompressThreshold long — ordinary bodies
+// stay exactly as given, so SearchHistory's body/response_body ILIKE still
+// matches their text. Only the rare multi-MB body pays the cost of
+// becoming unmatchable by ILIKE, in exchange for streamCompressBody never
+// holding it fully in memory while compressing it.
+func (p *postgresStore) SaveRequest(method, url string, headers map[string]string, body, responseBody string, statusCode int, timingMs int64) (string, error) {
+	id := uuid.New().String()
+	createdAt := time.Now().Unix()
+
+	safeHeaders := filterSensitiveHeaders(headers)
+	headersJSON, err := json.Marshal(safeHeaders)
+	if err != nil {
+		headersJSON = []byte("{}")
+	}
+
+	storedBody := body
+	if p.secretsMgr != nil && p.bodyHasSecretField(headers, body) {
+		if encrypted, err := p.secretsMgr.Encrypt(body); err == nil {
+			storedBody = encrypted
+		}
+	} else {
+		storedBody = streamCompressBody(storedBody)
+	}
+	storedResponseBody := streamCompressBody(responseBody)
+
+	if err := p.exec(
+		"INSERT INTO history (id, method, url, headers, body, response_body, status_code, timing_ms, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		id, method, url, string(headersJSON), storedBody, storedResponseBody, statusCode, timingMs, createdAt,
+	); err != nil {
+		return "", fmt.Errorf("failed to save request: %w", err)
+	}
+
+	return id, nil
+}
+
+func (p *postgresStore) scanHistoryRows(rows pgx.Rows) ([]HistoryItem, error) {
+	items := make([]HistoryItem, 0)
+	for rows.Next() {
+		var item HistoryItem
+		var headersJSON string
+		var responseBody sql.NullString
+		if err := rows.Scan(&item.ID, &item.Method, &item.URL, &headersJSON, &item.Body, &responseBody, &item.StatusCode, &item.TimingMs, &item.CreatedAt); err != nil {
+			continue
+		}
+		if headersJSON != "" {
+			json.Unmarshal([]byte(headersJSON), &item.Headers)
+		}
+		if item.Headers == nil {
+			item.Headers = make(map[string]string)
+		}
+		item.Body = p.decodeHistoryBody(item.Body)
+		item.ResponseBody = decodeStoredBody(responseBody.String)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (p *postgresStore) GetHistory(limit int, search string) ([]HistoryItem, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := "SELECT id, method, url, headers, body, response_body, status_code, timing_ms, created_at FROM history"
+	args := []interface{}{}
+	if search != "" {
+		query += " WHERE url LIKE ? OR method LIKE ?"
+		pattern := "%" + search + "%"
+		args = append(args, pattern, pattern)
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := p.pool.Query(context.Background(), rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	return p.scanHistoryRows(rows)
+}
+
+func (p *postgresStore) GetHistoryItem(id string) (*HistoryItem, error) {
+	var item HistoryItem
+	var headersJSON string
+	var responseBody sql.NullString
+	err := p.pool.QueryRow(context.Background(), rebind(
+		"SELECT id, method, url, headers, body, response_body, status_code, timing_ms, created_at FROM history WHERE id = ?"),
+		id,
+	).Scan(&item.ID, &item.Method, &item.URL, &headersJSON, &item.Body, &responseBody, &item.StatusCode, &item.TimingMs, &item.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history item: %w", err)
+	}
+	if headersJSON != "" {
+		json.Unmarshal([]byte(headersJSON), &item.Headers)
+	}
+	if item.Headers == nil {
+		item.Headers = make(map[string]string)
+	}
+	item.Body = p.decodeHistoryBody(item.Body)
+	item.ResponseBody = decodeStoredBody(responseBody.String)
+	return &item, nil
+}
+
+func (p *postgresStore) DeleteHistoryItem(id string) error {
+	return p.exec("DELETE FROM history WHERE id = ?", id)
+}
+
+func (p *postgresStore) ClearHistory() error {
+	return p.exec("DELETE FROM history")
+}
+
+// SearchHistory matches free-text terms against url, method, body, and
+// response_body with ILIKE rather than FTS5's ranked MATCH (see the gaps
+// noted on postgresStore), applying the same structured filters as the
+// SQLite store via the shared appendHistoryFilters helper.
+func (p *postgresStore) SearchHistory(query string, filters SearchFilters, limit int) ([]HistoryItem, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	conditions := []string{"(url ILIKE ? OR method ILIKE ? OR body ILIKE ? OR response_body ILIKE ?)"}
+	pattern := "%" + query + "%"
+	args := []interface{}{pattern, pattern, pattern, pattern}
+
+	conditions, args = appendHistoryFilters(conditions, args, filters, "")
+
+	sqlQuery := "SELECT id, method, url, headers, body, response_body, status_code, timing_ms, created_at FROM history WHERE " +
+		strings.Join(conditions, " AND ") + " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := p.pool.Query(context.Background(), rebind(sqlQuery), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search history: %w", err)
+	}
+	defer rows.Close()
+
+	return p.scanHistoryRows(rows)
+}
+
+// ============================================================================
+// Collections
+// ============================================================================
+
+func (p *postgresStore) CreateCollection(name string) (string, error) {
+	id := uuid.New().String()
+	now := time.Now().Unix()
+	if err := p.exec("INSERT INTO collections (id, name, created_at, updated_at) VALUES (?, ?, ?, ?)", id, name, now, now); err != nil {
+		return "", fmt.Errorf("failed to create collection: %w", err)
+	}
+	return id, nil
+}
+
+func (p *postgresStore) GetCollections() ([]Collection, error) {
+	rows, err := p.pool.Query(context.Background(), "SELECT id, name, created_at, updated_at FROM collections ORDER BY name ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collections: %w", err)
+	}
+	defer rows.Close()
+
+	collections := make([]Collection, 0)
+	for rows.Next() {
+		var c Collection
+		if err := rows.Scan(&c.ID, &c.Name, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			continue
+		}
+		collections = append(collections, c)
+	}
+	return collections, nil
+}
+
+func (p *postgresStore) GetCollection(id string) (*Collection, error) {
+	var c Collection
+	err := p.pool.QueryRow(context.Background(), rebind("SELECT id, name, created_at, updated_at FROM collections WHERE id = ?"), id).
+		Scan(&c.ID, &c.Name, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	return &c, nil
+}
+
+func (p *postgresStore) RenameCollection(id, name string) error {
+	return p.exec("UPDATE collections SET name = ?, updated_at = ? WHERE id = ?", name, time.Now().Unix(), id)
+}
+
+func (p *postgresStore) DeleteCollection(id string) error {
+	return p.exec("DELETE FROM collections WHERE id = ?", id)
+}
+
+// ============================================================================
+// Saved Requests
+// ============================================================================
+
+func (p *postgresStore) SaveRequestToCollection(collectionID, name, method, url string, headers map[string]string, body string) (string, error) {
+	id := uuid.New().String()
+	now := time.Now().Unix()
+	headersJSON, err := json.Marshal(headers)
+	if err != nil {
+		headersJSON = []byte("{}")
+	}
+	if err := p.exec(
+		"INSERT INTO saved_requests (id, collection_id, name, method, url, headers, body, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		id, collectionID, name, method, url, string(headersJSON), body, now, now,
+	); err != nil {
+		return "", fmt.Errorf("failed to save request: %w", err)
+	}
+	return id, nil
+}
+
+func (p *postgresStore) scanSavedRequestRows(rows pgx.Rows) []SavedRequest {
+	requests := make([]SavedRequest, 0)
+	for rows.Next() {
+		var r SavedRequest
+		var headersJSON string
+		var folderID sql.NullString
+		if err := rows.Scan(&r.ID, &r.CollectionID, &folderID, &r.Name, &r.Method, &r.URL, &headersJSON, &r.Body, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			continue
+		}
+		r.FolderID = folderID.String
+		if headersJSON != "" {
+			json.Unmarshal([]byte(headersJSON), &r.Headers)
+		}
+		if r.Headers == nil {
+			r.Headers = make(map[string]string)
+		}
+		requests = append(requests, r)
+	}
+	return requests
+}
+
+func (p *postgresStore) GetCollectionRequests(collectionID string) ([]SavedRequest, error) {
+	rows, err := p.pool.Query(context.Background(), rebind(
+		"SELECT id, collection_id, folder_id, name, method, url, headers, body, created_at, updated_at FROM saved_requests WHERE collection_id = ? ORDER BY name ASC"),
+		collectionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saved requests: %w", err)
+	}
+	defer rows.Close()
+	return p.scanSavedRequestRows(rows), nil
+}
+
+func (p *postgresStore) GetSavedRequest(id string) (*SavedRequest, error) {
+	var r SavedRequest
+	var headersJSON string
+	var folderID sql.NullString
+	err := p.pool.QueryRow(context.Background(), rebind(
+		"SELECT id, collection_id, folder_id, name, method, url, headers, body, created_at, updated_at FROM saved_requests WHERE id = ?"),
+		id,
+	).Scan(&r.ID, &r.CollectionID, &folderID, &r.Name, &r.Method, &r.URL, &headersJSON, &r.Body, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved request: %w", err)
+	}
+	r.FolderID = folderID.String
+	if headersJSON != "" {
+		json.Unmarshal([]byte(headersJSON), &r.Headers)
+	}
+	if r.Headers == nil {
+		r.Headers = make(map[string]string)
+	}
+	return &r, nil
+}
+
+func (p *postgresStore) UpdateSavedRequest(id, name, method, url string, headers map[string]string, body string) error {
+	headersJSON, err := json.Marshal(headers)
+	if err != nil {
+		headersJSON = []byte("{}")
+	}
+	return p.exec(
+		"UPDATE saved_requests SET name = ?, method = ?, url = ?, headers = ?, body = ?, updated_at = ? WHERE id = ?",
+		name, method, url, string(headersJSON), body, time.Now().Unix(), id,
+	)
+}
+
+func (p *postgresStore) MoveSavedRequest(id, newCollectionID string) error {
+	return p.exec("UPDATE saved_requests SET collection_id = ?, updated_at = ? WHERE id = ?", newCollectionID, time.Now().Unix(), id)
+}
+
+func (p *postgresStore) DeleteSavedRequest(id string) error {
+	return p.exec("DELETE FROM saved_requests WHERE id = ?", id)
+}
+
+// ============================================================================
+// Folders
+// ============================================================================
+
+func (p *postgresStore) CreateFolder(collectionID, parentID, name string) (string, error) {
+	id := uuid.New().String()
+	path := id
+
+	var parentIDArg interface{}
+	if parentID != "" {
+		var parentPath string
+		if err := p.pool.QueryRow(context.Background(), rebind("SELECT path FROM folders WHERE id = ? AND collection_id = ?"), parentID, collectionID).Scan(&parentPath); err != nil {
+			return "", fmt.Errorf("failed to look up parent folder: %w", err)
+		}
+		path = parentPath + "/" + id
+		parentIDArg = parentID
+	}
+
+	var position int
+	if err := p.pool.QueryRow(context.Background(), rebind(
+		"SELECT COALESCE(MAX(position), -1) + 1 FROM folders WHERE collection_id = ? AND parent_id IS ?"),
+		collectionID, parentIDArg,
+	).Scan(&position); err != nil {
+		position = 0
+	}
+
+	if err := p.exec(
+		"INSERT INTO folders (id, collection_id, parent_id, name, path, position) VALUES (?, ?, ?, ?, ?, ?)",
+		id, collectionID, parentIDArg, name, path, position,
+	); err != nil {
+		return "", fmt.Errorf("failed to create folder: %w", err)
+	}
+	return id, nil
+}
+
+func (p *postgresStore) MoveFolder(id, newParentID string) error {
+	var oldPath, collectionID string
+	if err := p.pool.QueryRow(context.Background(), rebind("SELECT path, collection_id FROM folders WHERE id = ?"), id).Scan(&oldPath, &collectionID); err != nil {
+		return fmt.Errorf("failed to look up folder: %w", err)
+	}
+
+	var newParentPath string
+	var newParentIDArg interface{}
+	if newParentID != "" {
+		var newParentCollectionID string
+		if err := p.pool.QueryRow(context.Background(), rebind("SELECT path, collection_id FROM folders WHERE id = ?"), newParentID).Scan(&newParentPath, &newParentCollectionID); err != nil {
+			return fmt.Errorf("failed to look up new parent folder: %w", err)
+		}
+		if newParentCollectionID != collectionID {
+			return fmt.Errorf("cannot move folder %s into a folder from a different collection", id)
+		}
+		if newParentPath == oldPath || strings.HasPrefix(newParentPath, oldPath+"/") {
+			return fmt.Errorf("cannot move folder %s into its own subtree", id)
+		}
+		newParentIDArg = newParentID
+	}
+
+	newPath := id
+	if newParentPath != "" {
+		newPath = newParentPath + "/" + id
+	}
+
+	ctx := context.Background()
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, rebind("UPDATE folders SET path = REPLACE(path, ?, ?) WHERE path = ? OR path LIKE ?"),
+		oldPath, newPath, oldPath, oldPath+"/%",
+	); err != nil {
+		return fmt.Errorf("failed to rewrite folder subtree: %w", err)
+	}
+	if _, err := tx.Exec(ctx, rebind("UPDATE folders SET parent_id = ? WHERE id = ?"), newParentIDArg, id); err != nil {
+		return fmt.Errorf("failed to reparent folder: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (p *postgresStore) GetFolderTree(collectionID string) (*FolderNode, error) {
+	root := &FolderNode{
+		Folder:   Folder{CollectionID: collectionID},
+		Requests: []SavedRequest{},
+		Children: []*FolderNode{},
+	}
+
+	rows, err := p.pool.Query(context.Background(), rebind(
+		"SELECT id, collection_id, parent_id, name, path, position FROM folders WHERE collection_id = ? ORDER BY path ASC"),
+		collectionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query folders: %w", err)
+	}
+
+	nodes := make(map[string]*FolderNode)
+	for rows.Next() {
+		var f Folder
+		var parentID sql.NullString
+		if err := rows.Scan(&f.ID, &f.CollectionID, &parentID, &f.Name, &f.Path, &f.Position); err != nil {
+			continue
+		}
+		f.ParentID = parentID.String
+
+		node := &FolderNode{Folder: f, Requests: []SavedRequest{}, Children: []*FolderNode{}}
+		nodes[f.ID] = node
+
+		if parent, ok := nodes[f.ParentID]; f.ParentID != "" && ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			root.Children = append(root.Children, node)
+		}
+	}
+	rows.Close()
+
+	requests, err := p.GetCollectionRequests(collectionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range requests {
+		if node, ok := nodes[r.FolderID]; r.FolderID != "" && ok {
+			node.Requests = append(node.Requests, r)
+		} else {
+			root.Requests = append(root.Requests, r)
+		}
+	}
+
+	return root, nil
+}
+
+func (p *postgresStore) MoveSavedRequestToFolder(reqID, folderID string) error {
+	var folderIDArg interface{}
+	if folderID != "" {
+		folderIDArg = folderID
+	}
+	return p.exec("UPDATE saved_requests SET folder_id = ?, updated_at = ? WHERE id = ?", folderIDArg, time.Now().Unix(), reqID)
+}
+
+// ============================================================================
+// Collection export/import
+// ============================================================================
+
+func (p *postgresStore) ExportCollection(id string) (*CollectionExport, error) {
+	collection, err := p.GetCollection(id)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := p.GetFolderTree(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CollectionExport{
+		Name:     collection.Name,
+		Requests: tree.Requests,
+		Folders:  exportFolderNodes(tree.Children),
+	}, nil
+}
+
+func (p *postgresStore) ImportCollection(data *CollectionExport) (string, error) {
+	collectionID, err := p.CreateCollection(data.Name)
+	if err != nil {
+		return "", err
+	}
+
+	for _, req := range data.Requests {
+		if _, err := p.SaveRequestToCollection(collectionID, req.Name, req.Method, req.URL, req.Headers, req.Body); err != nil {
+			continue
+		}
+	}
+
+	for _, folder := range data.Folders {
+		p.importFolderExport(collectionID, "", folder)
+	}
+
+	return collectionID, nil
+}
+
+func (p *postgresStore) importFolderExport(collectionID, parentID string, folder FolderExport) {
+	folderID, err := p.CreateFolder(collectionID, parentID, folder.Name)
+	if err != nil {
+		return
+	}
+
+	for _, req := range folder.Requests {
+		reqID, err := p.SaveRequestToCollection(collectionID, req.Name, req.Method, req.URL, req.Headers, req.Body)
+		if err != nil {
+			continue
+		}
+		p.MoveSavedRequestToFolder(reqID, folderID)
+	}
+
+	for _, child := range folder.Folders {
+		p.importFolderExport(collectionID, folderID, child)
+	}
+}
+
+// ============================================================================
+// Environments
+// ============================================================================
+
+func (p *postgresStore) CreateEnvironment(name string) (string, error) {
+	id := uuid.New().String()
+	now := time.Now().Unix()
+	if err := p.exec("INSERT INTO environments (id, name, is_active, is_global, created_at, updated_at) VALUES (?, ?, FALSE, FALSE, ?, ?)", id, name, now, now); err != nil {
+		return "", fmt.Errorf("failed to create environment: %w", err)
+	}
+	return id, nil
+}
+
+func (p *postgresStore) GetEnvironments() ([]Environment, error) {
+	rows, err := p.pool.Query(context.Background(), "SELECT id, name, is_active, is_global, created_at, updated_at FROM environments ORDER BY name ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query environments: %w", err)
+	}
+	defer rows.Close()
+
+	envs := make([]Environment, 0)
+	for rows.Next() {
+		var e Environment
+		if err := rows.Scan(&e.ID, &e.Name, &e.IsActive, &e.IsGlobal, &e.CreatedAt, &e.UpdatedAt); err != nil {
+			continue
+		}
+		envs = append(envs, e)
+	}
+	return envs, nil
+}
+
+func (p *postgresStore) GetEnvironment(id string) (*Environment, error) {
+	var e Environment
+	err := p.pool.QueryRow(context.Background(), rebind("SELECT id, name, is_active, is_global, created_at, updated_at FROM environments WHERE id = ?"), id).
+		Scan(&e.ID, &e.Name, &e.IsActive, &e.IsGlobal, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environment: %w", err)
+	}
+	return &e, nil
+}
+
+func (p *postgresStore) GetActiveEnvironment() (*Environment, error) {
+	var e Environment
+	err := p.pool.QueryRow(context.Background(), "SELECT id, name, is_active, is_global, created_at, updated_at FROM environments WHERE is_active = TRUE").
+		Scan(&e.ID, &e.Name, &e.IsActive, &e.IsGlobal, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active environment: %w", err)
+	}
+	return &e, nil
+}
+
+func (p *postgresStore) GetGlobalEnvironment() (*Environment, error) {
+	var e Environment
+	err := p.pool.QueryRow(context.Background(), "SELECT id, name, is_active, is_global, created_at, updated_at FROM environments WHERE is_global = TRUE").
+		Scan(&e.ID, &e.Name, &e.IsActive, &e.IsGlobal, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get global environment: %w", err)
+	}
+	return &e, nil
+}
+
+func (p *postgresStore) SetActiveEnvironment(id string) error {
+	ctx := context.Background()
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "UPDATE environments SET is_active = FALSE"); err != nil {
+		return fmt.Errorf("failed to deactivate environments: %w", err)
+	}
+	if id != "" {
+		if _, err := tx.Exec(ctx, rebind("UPDATE environments SET is_active = TRUE, updated_at = ? WHERE id = ?"), time.Now().Unix(), id); err != nil {
+			return fmt.Errorf("failed to activate environment: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (p *postgresStore) SetGlobalEnvironment(id string) error {
+	ctx := context.Background()
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "UPDATE environments SET is_global = FALSE"); err != nil {
+		return fmt.Errorf("failed to clear global environment: %w", err)
+	}
+	if id != "" {
+		if _, err := tx.Exec(ctx, rebind("UPDATE environments SET is_global = TRUE, updated_at = ? WHERE id = ?"), time.Now().Unix(), id); err != nil {
+			return fmt.Errorf("failed to set global environment: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (p *postgresStore) RenameEnvironment(id, name string) error {
+	return p.exec("UPDATE environments SET name = ?, updated_at = ? WHERE id = ?", name, time.Now().Unix(), id)
+}
+
+func (p *postgresStore) DeleteEnvironment(id string) error {
+	return p.exec("DELETE FROM environments WHERE id = ?", id)
+}
+
+// ============================================================================
+// Environment Variables
+// ============================================================================
+
+func (p *postgresStore) sealIfSecret(value, varType string) (string, error) {
+	if varType != VariableTypeSecret {
+		return value, nil
+	}
+	if p.secretsMgr == nil {
+		return "", fmt.Errorf("cannot store secret variable: encryption manager is unavailable")
+	}
+	return p.secretsMgr.Encrypt(value)
+}
+
+func (p *postgresStore) SetEnvironmentVariable(environmentID, key, value, varType string, enabled bool) (string, error) {
+	if varType == "" {
+		varType = VariableTypeString
+	}
+	storedValue, err := p.sealIfSecret(value, varType)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().Unix()
+	ctx := context.Background()
+
+	tag, err := p.pool.Exec(ctx, rebind(
+		"UPDATE environment_variables SET value = ?, enabled = ?, type = ?, updated_at = ? WHERE environment_id = ? AND key = ?"),
+		storedValue, enabled, varType, now, environmentID, key,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to update variable: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		var id string
+		if err := p.pool.QueryRow(ctx, rebind("SELECT id FROM environment_variables WHERE environment_id = ? AND key = ?"), environmentID, key).Scan(&id); err != nil {
+			return "", err
+		}
+		if err := p.snapshotEnvironment(environmentID); err != nil {
+			return "", err
+		}
+		return id, nil
+	}
+
+	id := uuid.New().String()
+	if err := p.exec(
+		"INSERT INTO environment_variables (id, environment_id, key, value, enabled, type, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		id, environmentID, key, storedValue, enabled, varType, now, now,
+	); err != nil {
+		return "", fmt.Errorf("failed to create variable: %w", err)
+	}
+	if err := p.snapshotEnvironment(environmentID); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// postgresRevisionAuthor tags every environment_revisions row this store
+// writes. postgresStore's own originID (see DeviceID) identifies this
+// *server process* for sync purposes, but every client talks to the same
+// shared backend through it, so there's still no per-client identity to
+// attribute a revision to — postgresRevisionAuthor stays a fixed label
+// until individual clients authenticate to this store some other way.
+const postgresRevisionAuthor = "postgres"
+
+// snapshotEnvironment is SetEnvironmentVariable/DeleteEnvironmentVariable/
+// RollbackEnvironment's Postgres counterpart to sqliteStore.snapshotEnvironment:
+// records environmentID's current variables as the next revision and prunes
+// revisions past maxEnvironmentRevisions.
+func (p *postgresStore) snapshotEnvironment(environmentID string) error {
+	vars, err := p.GetEnvironmentVariables(environmentID)
+	if err != nil {
+		return fmt.Errorf("failed to read variables for revision snapshot: %w", err)
+	}
+
+	snapshotJSON, err := json.Marshal(vars)
+	if err != nil {
+		return fmt.Errorf("failed to marshal revision snapshot: %w", err)
+	}
+
+	ctx := context.Background()
+	var nextRevision int
+	err = p.pool.QueryRow(ctx, rebind(
+		"SELECT COALESCE(MAX(revision), 0) + 1 FROM environment_revisions WHERE environment_id = ?"),
+		environmentID,
+	).Scan(&nextRevision)
+	if err != nil {
+		return fmt.Errorf("failed to compute next revision: %w", err)
+	}
+
+	if err := p.exec(
+		"INSERT INTO environment_revisions (id, environment_id, revision, author, snapshot_json, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		uuid.New().String(), environmentID, nextRevision, postgresRevisionAuthor, string(snapshotJSON), time.Now().Unix(),
+	); err != nil {
+		return fmt.Errorf("failed to record environment revision: %w", err)
+	}
+
+	if err := p.exec(
+		`DELETE FROM environment_revisions WHERE environment_id = ? AND revision <= (
+			SELECT MAX(revision) - ? FROM environment_revisions WHERE environment_id = ?
+		)`,
+		environmentID, maxEnvironmentRevisions, environmentID,
+	); err != nil {
+		return fmt.Errorf("failed to prune old environment revisions: %w", err)
+	}
+
+	return nil
+}
+
+func (p *postgresStore) GetEnvironmentVariables(environmentID string) ([]EnvironmentVariable, error) {
+	rows, err := p.pool.Query(context.Background(), rebind(
+		"SELECT id, environment_id, key, value, enabled, type, tags, created_at, updated_at FROM environment_variables WHERE environment_id = ? ORDER BY key ASC"),
+		environmentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query variables: %w", err)
+	}
+	defer rows.Close()
+
+	vars := make([]EnvironmentVariable, 0)
+	for rows.Next() {
+		var v EnvironmentVariable
+		if err := rows.Scan(&v.ID, &v.EnvironmentID, &v.Key, &v.Value, &v.Enabled, &v.Type, &v.Tags, &v.CreatedAt, &v.UpdatedAt); err != nil {
+			continue
+		}
+		if v.IsSecret() && p.secretsMgr != nil {
+			if plaintext, err := p.secretsMgr.Decrypt(v.Value); err == nil {
+				v.Value = plaintext
+			}
+		}
+		vars = append(vars, v)
+	}
+	return vars, nil
+}
+
+// GetEnvironmentVariablesByTag returns environmentID's variables whose
+// comma-separated Tags include tag exactly.
+func (p *postgresStore) GetEnvironmentVariablesByTag(environmentID, tag string) ([]EnvironmentVariable, error) {
+	vars, err := p.GetEnvironmentVariables(environmentID)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]EnvironmentVariable, 0, len(vars))
+	for _, v := range vars {
+		if hasTag(v.Tags, tag) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered, nil
+}
+
+// SetVariableTags replaces a variable's comma-separated tag list.
+func (p *postgresStore) SetVariableTags(id, tags string) error {
+	return p.exec("UPDATE environment_variables SET tags = ?, updated_at = ? WHERE id = ?", tags, time.Now().Unix(), id)
+}
+
+// EnableEnvironmentVariables enables every variable in ids inside a single
+// transaction.
+func (p *postgresStore) EnableEnvironmentVariables(ids []string) error {
+	return p.bulkSetEnabled(ids, true)
+}
+
+// DisableEnvironmentVariables disables every variable in ids inside a
+// single transaction.
+func (p *postgresStore) DisableEnvironmentVariables(ids []string) error {
+	return p.bulkSetEnabled(ids, false)
+}
+
+func (p *postgresStore) bulkSetEnabled(ids []string, enabled bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, 0, len(ids)+2)
+	args = append(args, enabled, time.Now().Unix())
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+
+	query := rebind(fmt.Sprintf(
+		"UPDATE environment_variables SET enabled = ?, updated_at = ? WHERE id IN (%s)",
+		strings.Join(placeholders, ","),
+	))
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to bulk update variables: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// BulkDeleteEnvironmentVariables removes every variable in ids inside a
+// single transaction.
+func (p *postgresStore) BulkDeleteEnvironmentVariables(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := rebind(fmt.Sprintf("DELETE FROM environment_variables WHERE id IN (%s)", strings.Join(placeholders, ",")))
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to bulk delete variables: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (p *postgresStore) activeEnvironmentVariables() (map[string]string, error) {
+	return p.scopedEnvironmentVariables("is_active")
+}
+
+// scopedEnvironmentVariables returns all enabled variables, decrypted, for
+// the single environment flagged by flagColumn ("is_active" or
+// "is_global"). flagColumn is always a fixed literal from call sites in
+// this file, never caller input.
+func (p *postgresStore) scopedEnvironmentVariables(flagColumn string) (map[string]string, error) {
+	rows, err := p.pool.Query(context.Background(), fmt.Sprintf(`
+		SELECT ev.key, ev.value, ev.type
+		FROM environment_variables ev
+		JOIN environments e ON ev.environment_id = e.id
+		WHERE e.%s = TRUE AND ev.enabled = TRUE
+	`, flagColumn))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s variables: %w", flagColumn, err)
+	}
+	defer rows.Close()
+
+	vars := make(map[string]string)
+	for rows.Next() {
+		var key, value, varType string
+		if err := rows.Scan(&key, &value, &varType); err != nil {
+			continue
+		}
+		if varType == VariableTypeSecret && p.secretsMgr != nil {
+			if plaintext, err := p.secretsMgr.Decrypt(value); err == nil {
+				value = plaintext
+			}
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+func (p *postgresStore) GetActiveEnvironmentVariables() (map[string]string, error) {
+	return p.activeEnvironmentVariables()
+}
+
+// GetActiveEnvironmentVariablesDecrypted returns the same data as
+// GetActiveEnvironmentVariables. It exists as a separate name so callers on
+// the request-execution path can depend on a decryption guarantee distinct
+// from any future UI-facing summary that might want to keep secrets masked.
+func (p *postgresStore) GetActiveEnvironmentVariablesDecrypted() (map[string]string, error) {
+	return p.activeEnvironmentVariables()
+}
+
+// GetGlobalEnvironmentVariablesDecrypted returns all enabled variables for
+// the global (shared) environment, decrypted.
+func (p *postgresStore) GetGlobalEnvironmentVariablesDecrypted() (map[string]string, error) {
+	return p.scopedEnvironmentVariables("is_global")
+}
+
+// GetActiveEnvironmentVariablesByTags is a variant of
+// GetActiveEnvironmentVariables that only returns variables whose Tags
+// include at least one of tags. An empty tags slice matches nothing.
+func (p *postgresStore) GetActiveEnvironmentVariablesByTags(tags []string) (map[string]string, error) {
+	if len(tags) == 0 {
+		return map[string]string{}, nil
+	}
+
+	rows, err := p.pool.Query(context.Background(), `
+		SELECT ev.key, ev.value, ev.type, ev.tags
+		FROM environment_variables ev
+		JOIN environments e ON ev.environment_id = e.id
+		WHERE e.is_active = TRUE AND ev.enabled = TRUE
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active variables: %w", err)
+	}
+	defer rows.Close()
+
+	vars := make(map[string]string)
+	for rows.Next() {
+		var key, value, varType, varTags string
+		if err := rows.Scan(&key, &value, &varType, &varTags); err != nil {
+			continue
+		}
+		if !hasAnyTag(varTags, tags) {
+			continue
+		}
+		if varType == VariableTypeSecret && p.secretsMgr != nil {
+			if plaintext, err := p.secretsMgr.Decrypt(value); err == nil {
+				value = plaintext
+			}
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+func (p *postgresStore) DeleteEnvironmentVariable(id string) error {
+	var environmentID string
+	if err := p.pool.QueryRow(context.Background(), rebind("SELECT environment_id FROM environment_variables WHERE id = ?"), id).Scan(&environmentID); err != nil {
+		return fmt.Errorf("failed to look up variable's environment: %w", err)
+	}
+
+	if err := p.exec("DELETE FROM environment_variables WHERE id = ?", id); err != nil {
+		return err
+	}
+
+	return p.snapshotEnvironment(environmentID)
+}
+
+func (p *postgresStore) SetVariableType(id, varType string) error {
+	var value string
+	var currentType string
+	if err := p.pool.QueryRow(context.Background(), rebind("SELECT value, type FROM environment_variables WHERE id = ?"), id).Scan(&value, &currentType); err != nil {
+		return fmt.Errorf("failed to load variable: %w", err)
+	}
+	if currentType == varType {
+		return nil
+	}
+
+	var newValue string
+	var err error
+	if currentType == VariableTypeSecret {
+		if p.secretsMgr == nil {
+			return fmt.Errorf("cannot decrypt variable: encryption manager is unavailable")
+		}
+		newValue, err = p.secretsMgr.Decrypt(value)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt variable: %w", err)
+		}
+	} else {
+		newValue = value
+	}
+
+	newValue, err = p.sealIfSecret(newValue, varType)
+	if err != nil {
+		return err
+	}
+
+	return p.exec("UPDATE environment_variables SET value = ?, type = ?, updated_at = ? WHERE id = ?", newValue, varType, time.Now().Unix(), id)
+}
+
+func (p *postgresStore) RotateSecretsKey() error {
+	if p.secretsMgr == nil {
+		return fmt.Errorf("cannot rotate secrets key: encryption manager is unavailable")
+	}
+
+	oldKey, err := p.secretsMgr.RotateKey()
+	if err != nil {
+		return fmt.Errorf("failed to rotate secrets key: %w", err)
+	}
+
+	rows, err := p.pool.Query(context.Background(), "SELECT id, value FROM environment_variables WHERE type = 'secret'")
+	if err != nil {
+		return fmt.Errorf("failed to query secret variables: %w", err)
+	}
+
+	type secretRow struct{ id, value string }
+	var secretRows []secretRow
+	for rows.Next() {
+		var r secretRow
+		if err := rows.Scan(&r.id, &r.value); err != nil {
+			continue
+		}
+		secretRows = append(secretRows, r)
+	}
+	rows.Close()
+
+	for _, r := range secretRows {
+		reencrypted, err := p.secretsMgr.ReencryptWithOldKey(oldKey, r.value)
+		if err != nil {
+			return fmt.Errorf("failed to re-encrypt variable %s: %w", r.id, err)
+		}
+		if err := p.exec("UPDATE environment_variables SET value = ? WHERE id = ?", reencrypted, r.id); err != nil {
+			return fmt.Errorf("failed to persist rotated variable %s: %w", r.id, err)
+		}
+	}
+	return nil
+}
+
+// ListEnvironmentRevisions returns environmentID's revision history, newest
+// first, without their variable snapshots.
+func (p *postgresStore) ListEnvironmentRevisions(environmentID string) ([]EnvironmentRevision, error) {
+	rows, err := p.pool.Query(context.Background(), rebind(
+		"SELECT id, environment_id, revision, author, created_at FROM environment_revisions WHERE environment_id = ? ORDER BY revision DESC"),
+		environmentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query environment revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]EnvironmentRevision, 0)
+	for rows.Next() {
+		var r EnvironmentRevision
+		if err := rows.Scan(&r.ID, &r.EnvironmentID, &r.Revision, &r.Author, &r.CreatedAt); err != nil {
+			continue
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, nil
+}
+
+// GetEnvironmentRevision returns a single revision of environmentID,
+// including the full variable snapshot taken at that revision.
+func (p *postgresStore) GetEnvironmentRevision(environmentID string, revision int) (*EnvironmentRevision, error) {
+	var r EnvironmentRevision
+	var snapshotJSON string
+	err := p.pool.QueryRow(context.Background(), rebind(
+		"SELECT id, environment_id, revision, author, snapshot_json, created_at FROM environment_revisions WHERE environment_id = ? AND revision = ?"),
+		environmentID, revision,
+	).Scan(&r.ID, &r.EnvironmentID, &r.Revision, &r.Author, &snapshotJSON, &r.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load environment revision %d: %w", revision, err)
+	}
+
+	if err := json.Unmarshal([]byte(snapshotJSON), &r.Variables); err != nil {
+		return nil, fmt.Errorf("failed to decode environment revision snapshot: %w", err)
+	}
+	return &r, nil
+}
+
+// DiffEnvironmentRevisions compares revisions a and b of environmentID,
+// keyed by variable Key, and reports which keys were added, removed, or
+// changed value going from a to b.
+func (p *postgresStore) DiffEnvironmentRevisions(environmentID string, a, b int) (*EnvironmentDiff, error) {
+	revA, err := p.GetEnvironmentRevision(environmentID, a)
+	if err != nil {
+		return nil, err
+	}
+	revB, err := p.GetEnvironmentRevision(environmentID, b)
+	if err != nil {
+		return nil, err
+	}
+
+	byKeyA := make(map[string]string, len(revA.Variables))
+	for _, v := range revA.Variables {
+		byKeyA[v.Key] = v.Value
+	}
+
+	diff := &EnvironmentDiff{}
+	seen := make(map[string]bool, len(revB.Variables))
+	for _, v := range revB.Variables {
+		seen[v.Key] = true
+		oldValue, ok := byKeyA[v.Key]
+		if !ok {
+			diff.Added = append(diff.Added, v.Key)
+			continue
+		}
+		if oldValue != v.Value {
+			diff.Changed = append(diff.Changed, v.Key)
+		}
+	}
+	for _, v := range revA.Variables {
+		if !seen[v.Key] {
+			diff.Removed = append(diff.Removed, v.Key)
+		}
+	}
+	return diff, nil
+}
+
+// RollbackEnvironment atomically rewrites environmentID's current variables
+// to match the snapshot recorded at revision, then records the rollback
+// itself as a new revision.
+func (p *postgresStore) RollbackEnvironment(environmentID string, revision int) error {
+	target, err := p.GetEnvironmentRevision(environmentID, revision)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, rebind("DELETE FROM environment_variables WHERE environment_id = ?"), environmentID); err != nil {
+		return fmt.Errorf("failed to clear current variables: %w", err)
+	}
+
+	now := time.Now().Unix()
+	for _, v := range target.Variables {
+		storedValue, err := p.sealIfSecret(v.Value, v.Type)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, rebind(
+			"INSERT INTO environment_variables (id, environment_id, key, value, enabled, type, tags, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"),
+			uuid.New().String(), environmentID, v.Key, storedValue, v.Enabled, v.Type, v.Tags, now, now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to restore variable %s: %w", v.Key, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	return p.snapshotEnvironment(environmentID)
+}
+
+func (p *postgresStore) ExportEnvironment(id string) (*EnvironmentExport, error) {
+	env, err := p.GetEnvironment(id)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := p.GetEnvironmentVariables(id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range vars {
+		if vars[i].IsSecret() {
+			vars[i].Value = secretPlaceholder
+		}
+	}
+	return &EnvironmentExport{Name: env.Name, Variables: vars}, nil
+}
+
+func (p *postgresStore) ImportEnvironment(data *EnvironmentExport) (string, error) {
+	environmentID, err := p.CreateEnvironment(data.Name)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range data.Variables {
+		if v.IsSecret() && v.Value == secretPlaceholder {
+			continue
+		}
+		if _, err := p.SetEnvironmentVariable(environmentID, v.Key, v.Value, v.Type, v.Enabled); err != nil {
+			continue
+		}
+	}
+	return environmentID, nil
+}
+
+// ============================================================================
+// Cookies
+// ============================================================================
+
+func (p *postgresStore) UpsertCookie(environmentID string, c Cookie) error {
+	now := time.Now().Unix()
+	return p.exec(
+		`INSERT INTO cookies (id, environment_id, domain, path, name, value, expires, secure, http_only, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(environment_id, domain, path, name) DO UPDATE SET
+		   value = excluded.value,
+		   expires = excluded.expires,
+		   secure = excluded.secure,
+		   http_only = excluded.http_only,
+		   updated_at = excluded.updated_at`,
+		uuid.New().String(), environmentID, c.Domain, c.Path, c.Name, c.Value, c.Expires, c.Secure, c.HTTPOnly, now, now,
+	)
+}
+
+func (p *postgresStore) GetCookies(environmentID string) ([]Cookie, error) {
+	rows, err := p.pool.Query(context.Background(), rebind(
+		"SELECT domain, path, name, value, expires, secure, http_only FROM cookies WHERE environment_id = ? ORDER BY domain ASC, name ASC"),
+		environmentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cookies: %w", err)
+	}
+	defer rows.Close()
+
+	cookies := make([]Cookie, 0)
+	for rows.Next() {
+		var c Cookie
+		if err := rows.Scan(&c.Domain, &c.Path, &c.Name, &c.Value, &c.Expires, &c.Secure, &c.HTTPOnly); err != nil {
+			continue
+		}
+		cookies = append(cookies, c)
+	}
+	return cookies, nil
+}
+
+func (p *postgresStore) DeleteCookie(environmentID, domain, name string) error {
+	return p.exec("DELETE FROM cookies WHERE environment_id = ? AND domain = ? AND name = ?", environmentID, domain, name)
+}
+
+func (p *postgresStore) ClearCookies(environmentID string) error {
+	return p.exec("DELETE FROM cookies WHERE environment_id = ?", environmentID)
+}
+
+// ============================================================================
+// Webhook configs
+// ============================================================================
+
+func (p *postgresStore) sealWebhookSecret(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if p.secretsMgr == nil {
+		return "", fmt.Errorf("cannot store webhook secret: encryption manager is unavailable")
+	}
+	return p.secretsMgr.Encrypt(value)
+}
+
+func (p *postgresStore) decryptWebhookSecrets(w *WebhookConfig) {
+	if p.secretsMgr == nil {
+		return
+	}
+	if w.AuthToken != "" {
+		if plaintext, err := p.secretsMgr.Decrypt(w.AuthToken); err == nil {
+			w.AuthToken = plaintext
+		}
+	}
+	if w.SigningSecret != "" {
+		if plaintext, err := p.secretsMgr.Decrypt(w.SigningSecret); err == nil {
+			w.SigningSecret = plaintext
+		}
+	}
+}
+
+func (p *postgresStore) CreateWebhookConfig(name, url, authScheme, authToken, signingSecret, eventFilter string, minStatusCode int, enabled bool) (string, error) {
+	sealedToken, err := p.sealWebhookSecret(authToken)
+	if err != nil {
+		return "", err
+	}
+	sealedSecret, err := p.sealWebhookSecret(signingSecret)
+	if err != nil {
+		return "", err
+	}
+
+	id := uuid.New().String()
+	now := time.Now().Unix()
+	err = p.exec(
+		`INSERT INTO webhook_configs
+		   (id, name, url, auth_scheme, auth_token, signing_secret, event_filter, min_status_code, enabled, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, name, url, authScheme, sealedToken, sealedSecret, eventFilter, minStatusCode, enabled, now, now,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create webhook config: %w", err)
+	}
+	return id, nil
+}
+
+func (p *postgresStore) GetWebhookConfigs() ([]WebhookConfig, error) {
+	rows, err := p.pool.Query(context.Background(), rebind(
+		`SELECT id, name, url, auth_scheme, auth_token, signing_secret, event_filter, min_status_code, enabled, created_at, updated_at
+		 FROM webhook_configs ORDER BY name ASC`),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook configs: %w", err)
+	}
+	defer rows.Close()
+
+	configs := make([]WebhookConfig, 0)
+	for rows.Next() {
+		var w WebhookConfig
+		if err := rows.Scan(&w.ID, &w.Name, &w.URL, &w.AuthScheme, &w.AuthToken, &w.SigningSecret,
+			&w.EventFilter, &w.MinStatusCode, &w.Enabled, &w.CreatedAt, &w.UpdatedAt); err != nil {
+			continue
+		}
+		p.decryptWebhookSecrets(&w)
+		configs = append(configs, w)
+	}
+	return configs, nil
+}
+
+func (p *postgresStore) GetEnabledWebhookConfigs() ([]WebhookConfig, error) {
+	configs, err := p.GetWebhookConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make([]WebhookConfig, 0, len(configs))
+	for _, w := range configs {
+		if w.Enabled {
+			enabled = append(enabled, w)
+		}
+	}
+	return enabled, nil
+}
+
+func (p *postgresStore) GetWebhookConfig(id string) (*WebhookConfig, error) {
+	var w WebhookConfig
+	err := p.pool.QueryRow(context.Background(), rebind(
+		`SELECT id, name, url, auth_scheme, auth_token, signing_secret, event_filter, min_status_code, enabled, created_at, updated_at
+		 FROM webhook_configs WHERE id = ?`), id,
+	).Scan(&w.ID, &w.Name, &w.URL, &w.AuthScheme, &w.AuthToken, &w.SigningSecret,
+		&w.EventFilter, &w.MinStatusCode, &w.Enabled, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook config: %w", err)
+	}
+	p.decryptWebhookSecrets(&w)
+	return &w, nil
+}
+
+func (p *postgresStore) UpdateWebhookConfig(id, name, url, authScheme, authToken, signingSecret, eventFilter string, minStatusCode int, enabled bool) error {
+	sealedToken, err := p.sealWebhookSecret(authToken)
+	if err != nil {
+		return err
+	}
+	sealedSecret, err := p.sealWebhookSecret(signingSecret)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	err = p.exec(
+		`UPDATE webhook_configs SET
+		   name = ?, url = ?, auth_scheme = ?, auth_token = ?, signing_secret = ?,
+		   event_filter = ?, min_status_code = ?, enabled = ?, updated_at = ?
+		 WHERE id = ?`,
+		name, url, authScheme, sealedToken, sealedSecret, eventFilter, minStatusCode, enabled, now, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook config: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresStore) DeleteWebhookConfig(id string) error {
+	return p.exec("DELETE FROM webhook_configs WHERE id = ?", id)
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// changeRecord is one row of the changelog table, as written by
+// sqliteStore.logChange and exchanged between replicator instances.
+type changeRecord struct {
+	ID          string `json:"id"`
+	Entity      string `json:"entity"`
+	Op          string `json:"op"`
+	PayloadJSON string `json:"payloadJson"`
+	Ts          int64  `json:"ts"`
+	OriginID    string `json:"originId"`
+}
+
+// replicator tails a sqliteStore's changelog table and exchanges it with a
+// remote volt-api instance over a plain HTTP endpoint ("POST /changelog" to
+// push, "GET /changelog?since=" to pull), enabling multi-device sync without
+// either side's existing Store methods needing to know sync exists.
+//
+// pull currently only lands the remote's rows into the local changelog
+// table so they're visible and deduplicated (by the changelog row's primary
+// key) across repeated pulls; it does not yet replay them into the local
+// history/collections/environments/... tables, so nothing in this package or
+// above it constructs a replicator yet. Turning a pulled changeRecord back
+// into the right mutation (and resolving conflicts when both sides changed
+// the same row) needs per-entity apply logic this first cut doesn't have —
+// this type stays unexported, and out of Store/Open's surface, until that
+// logic exists and pull actually does what its name says.
+type replicator struct {
+	db     *sqliteStore
+	remote string
+	client *http.Client
+
+	lastPushedTS int64
+	lastPulledTS int64
+}
+
+// newReplicator builds a replicator for db against a remote instance's base
+// URL (e.g. "https://team.example.com/sync").
+func newReplicator(db *sqliteStore, remoteURL string) *replicator {
+	return &replicator{
+		db:     db,
+		remote: strings.TrimSuffix(remoteURL, "/"),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// push sends every local changelog row written since the last successful
+// push to the remote's "/changelog" endpoint as a JSON array.
+func (r *replicator) push() error {
+	rows, err := r.db.db.Query(
+		"SELECT id, entity, op, payload_json, ts, origin_id FROM changelog WHERE ts > ? ORDER BY ts ASC",
+		r.lastPushedTS,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to read local changelog: %w", err)
+	}
+	defer rows.Close()
+
+	var records []changeRecord
+	for rows.Next() {
+		var rec changeRecord
+		if err := rows.Scan(&rec.ID, &rec.Entity, &rec.Op, &rec.PayloadJSON, &rec.Ts, &rec.OriginID); err != nil {
+			continue
+		}
+		records = append(records, rec)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("failed to marshal changelog records: %w", err)
+	}
+
+	resp, err := r.client.Post(r.remote+"/changelog", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to push changelog: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("remote rejected changelog push (%d): %s", resp.StatusCode, string(respBody))
+	}
+
+	r.lastPushedTS = records[len(records)-1].Ts
+	return nil
+}
+
+// pull fetches changelog rows the remote has written since the last
+// successful pull and merges them into the local changelog table (see the
+// replicator doc comment for what "merge" does and doesn't mean yet).
+func (r *replicator) pull() error {
+	resp, err := r.client.Get(fmt.Sprintf("%s/changelog?since=%d", r.remote, r.lastPulledTS))
+	if err != nil {
+		return fmt.Errorf("failed to pull changelog: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("remote rejected changelog pull (%d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var records []changeRecord
+	if err := json.NewDecoder(resp.Body).Decode(&records); err != nil {
+		return fmt.Errorf("failed to decode remote changelog: %w", err)
+	}
+
+	for _, rec := range records {
+		_, err := r.db.db.Exec(
+			"INSERT INTO changelog (id, entity, op, payload_json, ts, origin_id) VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT(id) DO NOTHING",
+			rec.ID, rec.Entity, rec.Op, rec.PayloadJSON, rec.Ts, rec.OriginID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store pulled changelog record %s: %w", rec.ID, err)
+		}
+		if rec.Ts > r.lastPulledTS {
+			r.lastPulledTS = rec.Ts
+		}
+	}
+
+	return nil
+}
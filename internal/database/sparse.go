@@ -0,0 +1,195 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+// ErrMissingData is returned by sparseDecompress when a bit vector
+// references a non-zero byte beyond what the payload actually carries —
+// a sign of a truncated or corrupted sparse payload.
+var ErrMissingData = errors.New("sparse payload references a byte beyond the data it carries")
+
+// ErrUnreferencedData is returned by sparseDecompress when the payload
+// has bytes left over once every bit the vector set has been consumed —
+// also a sign of a truncated or corrupted sparse payload.
+var ErrUnreferencedData = errors.New("sparse payload has bytes left over after decoding")
+
+// sparsePrefix marks a body preprocessed with the sparse bit-vector
+// scheme before being handed to gzip (see compressSparseBody).
+const sparsePrefix = "sparse:"
+
+// sparseZeroRatioThreshold is the minimum sampled zero-byte ratio (see
+// sparseSampleZeroRatio) a body needs before compressBody bothers with
+// the sparse bit-vector pass; below this the bitset overhead usually
+// costs more than it saves, and gzip alone does about as well.
+const sparseZeroRatioThreshold = 0.5
+
+// sparseSampleSize caps how many leading bytes sparseSampleZeroRatio
+// reads to estimate a body's zero-byte ratio, so the check stays cheap
+// even for very large bodies.
+const sparseSampleSize = 1024
+
+// sparseSampleZeroRatio estimates the fraction of zero bytes in data by
+// sampling its first sparseSampleSize bytes (or all of it, if shorter).
+func sparseSampleZeroRatio(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	sample := data
+	if len(sample) > sparseSampleSize {
+		sample = sample[:sparseSampleSize]
+	}
+	zero := 0
+	for _, b := range sample {
+		if b == 0 {
+			zero++
+		}
+	}
+	return float64(zero) / float64(len(sample))
+}
+
+// sparseCompress encodes data with a sparse bit-vector scheme inspired by
+// go-ethereum's bitutil.CompressBytes: a bit vector of length
+// (len(data)+7)/8, where bit i is set iff data[i] != 0, followed by the
+// concatenated non-zero bytes. The bit vector tends to be sparse too when
+// data is, so it is encoded the same way, recursively, down to a single
+// byte, which needs no bitset of its own. An all-zero input (at any
+// level) encodes to nothing, since every byte of it is implied by the
+// caller already knowing its length. The caller must track len(data)
+// separately (see compressSparseBody's varint prefix), since
+// sparseDecompress needs it to know both how many bytes to reconstruct
+// and where each level's bit vector ends and its non-zero-byte stream
+// begins.
+func sparseCompress(data []byte) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+	if len(data) == 1 {
+		if data[0] == 0 {
+			return nil
+		}
+		return append([]byte(nil), data...)
+	}
+
+	bitset := make([]byte, (len(data)+7)/8)
+	nonZero := make([]byte, 0, len(data))
+	for i, b := range data {
+		if b != 0 {
+			bitset[i/8] |= 1 << uint(i%8)
+			nonZero = append(nonZero, b)
+		}
+	}
+	if len(nonZero) == 0 {
+		return nil
+	}
+	return append(sparseCompress(bitset), nonZero...)
+}
+
+// sparseDecompress reverses sparseCompress for a payload known to decode
+// to exactly target bytes. An empty data with target > 0 means the
+// original value was entirely zero bytes (see sparseCompress).
+func sparseDecompress(data []byte, target int) ([]byte, error) {
+	if target == 0 {
+		if len(data) != 0 {
+			return nil, ErrUnreferencedData
+		}
+		return nil, nil
+	}
+	if len(data) == 0 {
+		return make([]byte, target), nil
+	}
+
+	out, used, err := sparseDecodeNonZero(data, target)
+	if err != nil {
+		return nil, err
+	}
+	if used != len(data) {
+		return nil, ErrUnreferencedData
+	}
+	return out, nil
+}
+
+// sparseDecodeNonZero decodes a target-byte segment that sparseCompress
+// is guaranteed to have encoded as non-empty: every recursive call here
+// is reached only because the level above it already confirmed there was
+// more data to read, which (by the same invariant sparseCompress relies
+// on) means this segment's own bit vector is non-empty too. It reports
+// how many bytes of data it consumed, so the caller one level up knows
+// where its own non-zero-byte stream begins.
+func sparseDecodeNonZero(data []byte, target int) ([]byte, int, error) {
+	if target == 1 {
+		if len(data) < 1 {
+			return nil, 0, ErrMissingData
+		}
+		return data[:1], 1, nil
+	}
+
+	bitsetLen := (target + 7) / 8
+	bitset, used, err := sparseDecodeNonZero(data, bitsetLen)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]byte, target)
+	for i := 0; i < target; i++ {
+		if bitset[i/8]&(1<<uint(i%8)) == 0 {
+			continue
+		}
+		if used >= len(data) {
+			return nil, 0, ErrMissingData
+		}
+		out[i] = data[used]
+		used++
+	}
+	return out, used, nil
+}
+
+// compressSparseBody runs data through sparseCompress and then gzip,
+// prefixing the result with sparsePrefix and a varint of len(data) so
+// decompressSparseBody can reverse both stages. It returns "" if the
+// result isn't worth using.
+func compressSparseBody(data []byte) string {
+	encoded, err := (gzipCodec{}).Encode(sparseCompress(data))
+	if err != nil {
+		return ""
+	}
+
+	var lenBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBuf[:], uint64(len(data)))
+	payload := append(append([]byte{}, lenBuf[:n]...), encoded...)
+
+	result := sparsePrefix + base64.StdEncoding.EncodeToString(payload)
+	if len(result) >= len(data) {
+		return ""
+	}
+	return result
+}
+
+// decompressSparseBody reverses compressSparseBody. ok is false if body
+// isn't a well-formed sparse payload, in which case the caller should
+// fall back to returning body unchanged.
+func decompressSparseBody(body string) (out string, ok bool) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(body, sparsePrefix))
+	if err != nil {
+		return "", false
+	}
+
+	target, n := binary.Uvarint(raw)
+	if n <= 0 {
+		return "", false
+	}
+
+	sparse, err := (gzipCodec{}).Decode(raw[n:])
+	if err != nil {
+		return "", false
+	}
+
+	decoded, err := sparseDecompress(sparse, int(target))
+	if err != nil {
+		return "", false
+	}
+	return string(decoded), true
+}
@@ -0,0 +1,169 @@
+package database
+
+import "fmt"
+
+// Store is the full persistence surface the rest of volt-api depends on.
+// *sqliteStore (SQLite, via New or Open with a "sqlite://" DSN) is the
+// reference implementation that every method below was originally written
+// against; Open can also return a Postgres-backed store for team/server
+// deployments, so a future sync feature doesn't have to fork every caller
+// of these methods.
+//
+// Import/export helpers for third-party formats (Postman, OpenAPI,
+// Insomnia) stay sqlite-specific for now: they aren't reachable from the
+// Wails-bound App yet, so there's no caller that needs them through this
+// interface, and pulling them in would double the surface of this first
+// cut for no present benefit.
+type Store interface {
+	// History
+	SaveRequest(method, url string, headers map[string]string, body, responseBody string, statusCode int, timingMs int64) (string, error)
+	GetHistory(limit int, search string) ([]HistoryItem, error)
+	GetHistoryItem(id string) (*HistoryItem, error)
+	DeleteHistoryItem(id string) error
+	ClearHistory() error
+	SearchHistory(query string, filters SearchFilters, limit int) ([]HistoryItem, error)
+	SetSecretBodyPatterns(patterns []string) error
+
+	// Collections
+	CreateCollection(name string) (string, error)
+	GetCollections() ([]Collection, error)
+	GetCollection(id string) (*Collection, error)
+	RenameCollection(id, name string) error
+	DeleteCollection(id string) error
+
+	// Saved requests
+	SaveRequestToCollection(collectionID, name, method, url string, headers map[string]string, body string) (string, error)
+	GetCollectionRequests(collectionID string) ([]SavedRequest, error)
+	GetSavedRequest(id string) (*SavedRequest, error)
+	UpdateSavedRequest(id, name, method, url string, headers map[string]string, body string) error
+	MoveSavedRequest(id, newCollectionID string) error
+	DeleteSavedRequest(id string) error
+
+	// Folders
+	CreateFolder(collectionID, parentID, name string) (string, error)
+	MoveFolder(id, newParentID string) error
+	GetFolderTree(collectionID string) (*FolderNode, error)
+	MoveSavedRequestToFolder(reqID, folderID string) error
+
+	// Collection export/import
+	ExportCollection(id string) (*CollectionExport, error)
+	ImportCollection(data *CollectionExport) (string, error)
+
+	// Environments
+	CreateEnvironment(name string) (string, error)
+	GetEnvironments() ([]Environment, error)
+	GetEnvironment(id string) (*Environment, error)
+	GetActiveEnvironment() (*Environment, error)
+	SetActiveEnvironment(id string) error
+	GetGlobalEnvironment() (*Environment, error)
+	SetGlobalEnvironment(id string) error
+	RenameEnvironment(id, name string) error
+	DeleteEnvironment(id string) error
+
+	// Environment variables
+	SetEnvironmentVariable(environmentID, key, value, varType string, enabled bool) (string, error)
+	GetEnvironmentVariables(environmentID string) ([]EnvironmentVariable, error)
+	GetEnvironmentVariablesByTag(environmentID, tag string) ([]EnvironmentVariable, error)
+	GetActiveEnvironmentVariables() (map[string]string, error)
+	GetActiveEnvironmentVariablesDecrypted() (map[string]string, error)
+	GetActiveEnvironmentVariablesByTags(tags []string) (map[string]string, error)
+	GetGlobalEnvironmentVariablesDecrypted() (map[string]string, error)
+	DeleteEnvironmentVariable(id string) error
+	SetVariableType(id, varType string) error
+	SetVariableTags(id, tags string) error
+	EnableEnvironmentVariables(ids []string) error
+	DisableEnvironmentVariables(ids []string) error
+	BulkDeleteEnvironmentVariables(ids []string) error
+	RotateSecretsKey() error
+
+	// Environment revision history
+	ListEnvironmentRevisions(environmentID string) ([]EnvironmentRevision, error)
+	GetEnvironmentRevision(environmentID string, revision int) (*EnvironmentRevision, error)
+	DiffEnvironmentRevisions(environmentID string, a, b int) (*EnvironmentDiff, error)
+	RollbackEnvironment(environmentID string, revision int) error
+
+	// Environment export/import
+	ExportEnvironment(id string) (*EnvironmentExport, error)
+	ImportEnvironment(data *EnvironmentExport) (string, error)
+
+	// Cookies
+	UpsertCookie(environmentID string, c Cookie) error
+	GetCookies(environmentID string) ([]Cookie, error)
+	DeleteCookie(environmentID, domain, name string) error
+	ClearCookies(environmentID string) error
+
+	// Webhook configs
+	CreateWebhookConfig(name, url, authScheme, authToken, signingSecret, eventFilter string, minStatusCode int, enabled bool) (string, error)
+	GetWebhookConfigs() ([]WebhookConfig, error)
+	GetEnabledWebhookConfigs() ([]WebhookConfig, error)
+	GetWebhookConfig(id string) (*WebhookConfig, error)
+	UpdateWebhookConfig(id, name, url, authScheme, authToken, signingSecret, eventFilter string, minStatusCode int, enabled bool) error
+	DeleteWebhookConfig(id string) error
+
+	// DeviceID returns this machine's stable per-device identifier, for
+	// callers (e.g. storage sync) that need one even against a shared
+	// backend like postgresStore.
+	DeviceID() string
+
+	Close() error
+}
+
+// var _ Store = (*sqliteStore)(nil) documents, at compile time, that the
+// SQLite-backed sqliteStore satisfies Store in full.
+var _ Store = (*sqliteStore)(nil)
+
+// var _ Store = (*postgresStore)(nil) documents the same for the Postgres
+// implementation.
+var _ Store = (*postgresStore)(nil)
+
+// Open opens a Store for dsn, picking the backend from its scheme:
+// "sqlite://path/to/file.db" (or a bare/unprefixed path, or "" for the
+// default OS data directory used by New) opens a SQLite-backed sqliteStore;
+// "postgres://" or "postgresql://" opens a pgx-backed postgresStore.
+func Open(dsn string) (Store, error) {
+	scheme, rest := splitDSN(dsn)
+
+	switch scheme {
+	case "", "sqlite":
+		if rest == "" {
+			return New()
+		}
+		return newSQLiteStoreAt(rest)
+	case "postgres", "postgresql":
+		return newPostgresStore(dsn)
+	default:
+		return nil, fmt.Errorf("unsupported store scheme %q", scheme)
+	}
+}
+
+// splitDSN splits dsn into its "scheme://" prefix (without "://") and the
+// remainder, or ("", dsn) if it has no recognized scheme.
+func splitDSN(dsn string) (scheme, rest string) {
+	const sep = "://"
+	for i := 0; i+len(sep) <= len(dsn); i++ {
+		if dsn[i:i+len(sep)] == sep {
+			return dsn[:i], dsn[i+len(sep):]
+		}
+	}
+	return "", dsn
+}
+
+// rebind rewrites a query written with SQLite-style "?" positional
+// placeholders into Postgres-style "$1", "$2", ... placeholders, so the
+// postgresStore can reuse the same dynamic filter-building logic (see
+// appendHistoryFilters) that sqliteStore uses, rather than maintaining a
+// second copy with different placeholder syntax.
+func rebind(query string) string {
+	out := make([]byte, 0, len(query)+8)
+	n := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] == '?' {
+			n++
+			out = append(out, '$')
+			out = append(out, []byte(fmt.Sprintf("%d", n))...)
+			continue
+		}
+		out = append(out, query[i])
+	}
+	return string(out)
+}
@@ -0,0 +1,379 @@
+package database
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressWriter and DecompressReader are the streaming counterparts to
+// compressBody/decompressBody (see compression.go), for callers that can't
+// afford a full in-memory copy of a multi-MB body. SaveRequest routes a
+// body through them (see streamCompressBody) once it clears
+// streamCompressThreshold, trading full-text searchability of that one
+// row (history_fts indexes whatever ends up in the body/response_body
+// columns, compressed bytes included) for bounded memory use on the rare
+// multi-MB payload; everything under the threshold is still stored and
+// indexed as plain text. They're also available directly to any caller
+// that already holds an io.Reader/io.Writer pair, such as export/import
+// paths working against files on disk.
+
+// streamChunkSize is the buffer size CompressWriter/DecompressReader use
+// between the caller and the underlying codec, so a multi-MB body never
+// needs a full in-memory copy to compress or decompress — only
+// streamChunkSize bytes are buffered at a time.
+const streamChunkSize = 64 * 1024
+
+// streamHeaderSize is the one-byte codec tag plus the 4-byte big-endian
+// dictionary ID every CompressWriter stream starts with.
+const streamHeaderSize = 5
+
+// noDictionaryID marks a stream written without a trained dictionary.
+const noDictionaryID uint32 = 0
+
+// streamCodecTags maps a Codec's Name to the single byte CompressWriter
+// writes at the start of its stream, so DecompressReader knows which
+// codec produced it without needing a string prefix (the streaming path
+// writes binary headers, not the text magic prefixes compressBody uses).
+var streamCodecTags = map[string]byte{
+	"gzip":    1,
+	"deflate": 2,
+	"zstd":    3,
+	"snappy":  4,
+}
+
+var streamCodecsByTag = func() map[byte]string {
+	byTag := make(map[byte]string, len(streamCodecTags))
+	for name, tag := range streamCodecTags {
+		byTag[tag] = name
+	}
+	return byTag
+}()
+
+// dictionaries holds preshared zstd dictionaries — each trained from a
+// sample of recent bodies (see RegisterDictionary) — keyed by the ID
+// CompressWriter embeds in its stream header. Keeping every registered
+// dictionary, not just the active one, means DecompressReader can still
+// decode streams written under a dictionary that has since rotated out.
+var dictionaries = struct {
+	mu     sync.RWMutex
+	active uint32
+	byID   map[uint32][]byte
+}{byID: map[uint32][]byte{}}
+
+// RegisterDictionary adds (or replaces) a trained zstd dictionary under
+// id and makes it the active dictionary CompressWriter embeds into new
+// zstd streams. It has no effect on other codecs, since gzip, deflate,
+// and snappy have no dictionary support in their Go implementations.
+func RegisterDictionary(id uint32, dict []byte) {
+	dictionaries.mu.Lock()
+	defer dictionaries.mu.Unlock()
+	dictionaries.byID[id] = dict
+	dictionaries.active = id
+}
+
+func lookupDictionary(id uint32) []byte {
+	dictionaries.mu.RLock()
+	defer dictionaries.mu.RUnlock()
+	return dictionaries.byID[id]
+}
+
+func activeDictionaryID() uint32 {
+	dictionaries.mu.RLock()
+	defer dictionaries.mu.RUnlock()
+	return dictionaries.active
+}
+
+// streamWriter wraps a codec-specific streaming writer together with the
+// bufio.Writer it feeds into, so Close can flush the buffer only after
+// the codec has finished writing its trailer.
+type streamWriter struct {
+	buf   *bufio.Writer
+	codec io.WriteCloser
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	return s.codec.Write(p)
+}
+
+func (s *streamWriter) Close() error {
+	if err := s.codec.Close(); err != nil {
+		return err
+	}
+	return s.buf.Flush()
+}
+
+// CompressWriter returns a streaming, chunked writer over w using the
+// active codec (see activeCodec): callers can Write a body of any size
+// without holding it fully in memory, unlike compressBody. The returned
+// writer must be Closed to flush the codec's trailer and the underlying
+// buffer. For zstd, the currently active dictionary (see
+// RegisterDictionary) is used if one is registered; its ID is written
+// into the stream's header so DecompressReader can find the matching
+// dictionary even after a newer one becomes active.
+//
+// Unlike compressBody, CompressWriter always compresses: callers
+// streaming a body don't know its final size up front, so there is no
+// minCompressSize check to apply here.
+func CompressWriter(w io.Writer) (io.WriteCloser, error) {
+	codec := activeCodec()
+	buf := bufio.NewWriterSize(w, streamChunkSize)
+
+	dictID := noDictionaryID
+	if codec.Name() == "zstd" {
+		dictID = activeDictionaryID()
+	}
+	if err := writeStreamHeader(buf, codec, dictID); err != nil {
+		return nil, err
+	}
+
+	codecWriter, err := newStreamEncoder(codec, buf, dictID)
+	if err != nil {
+		return nil, err
+	}
+	return &streamWriter{buf: buf, codec: codecWriter}, nil
+}
+
+// streamReader wraps a codec-specific streaming reader; Close releases
+// any resources the codec itself holds (zstd's Decoder in particular).
+type streamReader struct {
+	codec  io.Reader
+	closer func()
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	return s.codec.Read(p)
+}
+
+func (s *streamReader) Close() error {
+	if s.closer != nil {
+		s.closer()
+	}
+	if c, ok := s.codec.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// DecompressReader returns a streaming reader over r, a stream
+// previously written by CompressWriter: it reads the codec tag and
+// dictionary ID from the stream's header, then wraps r in that codec's
+// streaming decoder, using the matching registered dictionary (see
+// RegisterDictionary) for zstd streams that named one.
+func DecompressReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReaderSize(r, streamChunkSize)
+
+	codec, dictID, err := readStreamHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	codecReader, closer, err := newStreamDecoder(codec, br, dictID)
+	if err != nil {
+		return nil, err
+	}
+	return &streamReader{codec: codecReader, closer: closer}, nil
+}
+
+// writeStreamHeader writes the codec tag and dictionary ID CompressWriter
+// prefixes every stream with.
+func writeStreamHeader(w io.Writer, codec Codec, dictID uint32) error {
+	tag, ok := streamCodecTags[codec.Name()]
+	if !ok {
+		return fmt.Errorf("database: codec %q has no stream tag registered", codec.Name())
+	}
+
+	var header [streamHeaderSize]byte
+	header[0] = tag
+	binary.BigEndian.PutUint32(header[1:], dictID)
+	_, err := w.Write(header[:])
+	return err
+}
+
+// readStreamHeader reads back what writeStreamHeader wrote.
+func readStreamHeader(r io.Reader) (codec Codec, dictID uint32, err error) {
+	var header [streamHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, 0, fmt.Errorf("database: reading stream header: %w", err)
+	}
+
+	name, ok := streamCodecsByTag[header[0]]
+	if !ok {
+		return nil, 0, fmt.Errorf("database: unrecognized stream codec tag %d", header[0])
+	}
+	codec, ok = codecRegistry[name]
+	if !ok {
+		return nil, 0, fmt.Errorf("database: codec %q not registered", name)
+	}
+	return codec, binary.BigEndian.Uint32(header[1:]), nil
+}
+
+// newStreamEncoder builds the streaming io.WriteCloser for codec, writing
+// compressed output into w.
+func newStreamEncoder(codec Codec, w io.Writer, dictID uint32) (io.WriteCloser, error) {
+	switch codec.Name() {
+	case "gzip":
+		return gzip.NewWriter(w), nil
+	case "deflate":
+		return flate.NewWriter(w, flate.DefaultCompression)
+	case "zstd":
+		opts := []zstd.EOption{}
+		if dict := lookupDictionary(dictID); dict != nil {
+			opts = append(opts, zstd.WithEncoderDict(dict))
+		}
+		return zstd.NewWriter(w, opts...)
+	case "snappy":
+		return snappy.NewBufferedWriter(w), nil
+	default:
+		return nil, fmt.Errorf("database: codec %q has no streaming writer", codec.Name())
+	}
+}
+
+// newStreamDecoder builds the streaming io.Reader for codec, reading
+// compressed input from r. closer, if non-nil, releases any resources
+// the decoder holds beyond what a plain io.Closer would (zstd's Decoder
+// exposes Close with no error return, unlike io.Closer).
+func newStreamDecoder(codec Codec, r io.Reader, dictID uint32) (reader io.Reader, closer func(), err error) {
+	switch codec.Name() {
+	case "gzip":
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gr, nil, nil
+	case "deflate":
+		return flate.NewReader(r), nil, nil
+	case "zstd":
+		opts := []zstd.DOption{}
+		if dict := lookupDictionary(dictID); dict != nil {
+			opts = append(opts, zstd.WithDecoderDicts(dict))
+		}
+		zr, err := zstd.NewReader(r, opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zr, zr.Close, nil
+	case "snappy":
+		return snappy.NewReader(r), nil, nil
+	default:
+		return nil, nil, fmt.Errorf("database: codec %q has no streaming reader", codec.Name())
+	}
+}
+
+// streamedBodyPrefix marks a history body/response_body value that
+// streamCompressBody ran through CompressWriter, so streamDecompressBody
+// can recognize it the same way compressBody's codec prefixes let
+// decompressBody recognize a whole-string compressed body.
+const streamedBodyPrefix = "streamz:"
+
+// streamCompressThreshold is the size, in bytes, above which SaveRequest
+// routes a body through streamCompressBody instead of storing it as plain,
+// FTS-searchable text. It's set high enough that the overwhelming majority
+// of request/response bodies — the ones actually worth full-text search —
+// are unaffected; only the rare multi-MB payload pays the FTS-indexing
+// cost of being compressed, in exchange for bounded memory use while
+// compressing it.
+const streamCompressThreshold = 2 << 20 // 2 MiB
+
+// streamCompressBody compresses body via CompressWriter, streaming it
+// through in streamChunkSize pieces rather than handing the codec the
+// whole buffer at once, if body is at least streamCompressThreshold long;
+// shorter bodies are returned unchanged so they remain plain, FTS-indexable
+// text. The result is base64-encoded behind streamedBodyPrefix, the same
+// text-safe convention compressBody uses for its own codec prefixes, so it
+// can still round-trip through a TEXT column.
+func streamCompressBody(body string) string {
+	if len(body) < streamCompressThreshold {
+		return body
+	}
+
+	var buf bytes.Buffer
+	w, err := CompressWriter(&buf)
+	if err != nil {
+		return body
+	}
+
+	r := strings.NewReader(body)
+	chunk := make([]byte, streamChunkSize)
+	for {
+		n, rerr := r.Read(chunk)
+		if n > 0 {
+			if _, werr := w.Write(chunk[:n]); werr != nil {
+				return body
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return body
+		}
+	}
+	if err := w.Close(); err != nil {
+		return body
+	}
+
+	encoded := streamedBodyPrefix + base64.StdEncoding.EncodeToString(buf.Bytes())
+	if len(encoded) >= len(body) {
+		return body
+	}
+	return encoded
+}
+
+// streamDecompressBody reverses streamCompressBody. ok is false (and body
+// is returned unchanged) for any value streamCompressBody didn't produce,
+// so callers can chain it ahead of other decode steps unconditionally.
+func streamDecompressBody(body string) (decoded string, ok bool) {
+	if !strings.HasPrefix(body, streamedBodyPrefix) {
+		return body, false
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(body, streamedBodyPrefix))
+	if err != nil {
+		return body, false
+	}
+
+	r, err := DecompressReader(bytes.NewReader(raw))
+	if err != nil {
+		return body, false
+	}
+	defer r.Close()
+
+	var out bytes.Buffer
+	chunk := make([]byte, streamChunkSize)
+	for {
+		n, rerr := r.Read(chunk)
+		if n > 0 {
+			out.Write(chunk[:n])
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return body, false
+		}
+	}
+
+	return out.String(), true
+}
+
+// decodeStoredBody reverses streamCompressBody for a value read back from
+// the body or response_body column; anything streamCompressBody left
+// untouched (including a secret-encrypted request body, which
+// streamCompressBody never sees) is returned unchanged.
+func decodeStoredBody(body string) string {
+	if decoded, ok := streamDecompressBody(body); ok {
+		return decoded
+	}
+	return body
+}
@@ -0,0 +1,206 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook event filters, controlling which completed requests a
+// WebhookConfig is notified about.
+const (
+	WebhookEventAll     = "all"
+	WebhookEventSuccess = "success"
+	WebhookEventError   = "error"
+)
+
+// WebhookConfig is an outbound notification target that fires after every
+// completed HTTP request (see the app package's webhook delivery worker).
+// AuthToken and SigningSecret are encrypted at rest via the secrets
+// manager, the same as a secret environment variable, and come back
+// decrypted from GetWebhookConfigs/GetWebhookConfig so the UI can show and
+// re-save them.
+type WebhookConfig struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	URL           string `json:"url"`
+	AuthScheme    string `json:"authScheme"` // "", "bearer", or "basic"
+	AuthToken     string `json:"authToken"`
+	SigningSecret string `json:"signingSecret"`
+	EventFilter   string `json:"eventFilter"` // WebhookEventAll, WebhookEventSuccess, or WebhookEventError
+	MinStatusCode int    `json:"minStatusCode"`
+	Enabled       bool   `json:"enabled"`
+	CreatedAt     int64  `json:"createdAt"`
+	UpdatedAt     int64  `json:"updatedAt"`
+}
+
+// sealWebhookSecret encrypts value for storage, unless it's empty (nothing
+// to encrypt) or d has no secrets manager (in which case a non-empty value
+// can't be stored safely).
+func (d *sqliteStore) sealWebhookSecret(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if d.secretsMgr == nil {
+		return "", fmt.Errorf("cannot store webhook secret: encryption manager is unavailable")
+	}
+	return d.secretsMgr.Encrypt(value)
+}
+
+// CreateWebhookConfig stores a new webhook target and returns its ID.
+func (d *sqliteStore) CreateWebhookConfig(name, url, authScheme, authToken, signingSecret, eventFilter string, minStatusCode int, enabled bool) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	sealedToken, err := d.sealWebhookSecret(authToken)
+	if err != nil {
+		return "", err
+	}
+	sealedSecret, err := d.sealWebhookSecret(signingSecret)
+	if err != nil {
+		return "", err
+	}
+
+	id := uuid.New().String()
+	now := time.Now().Unix()
+
+	_, err = d.db.Exec(
+		`INSERT INTO webhook_configs
+		   (id, name, url, auth_scheme, auth_token, signing_secret, event_filter, min_status_code, enabled, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, name, url, authScheme, sealedToken, sealedSecret, eventFilter, minStatusCode, boolToInt(enabled), now, now,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create webhook config: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetWebhookConfigs returns every configured webhook, with secrets decrypted.
+func (d *sqliteStore) GetWebhookConfigs() ([]WebhookConfig, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	rows, err := d.db.Query(
+		`SELECT id, name, url, auth_scheme, auth_token, signing_secret, event_filter, min_status_code, enabled, created_at, updated_at
+		 FROM webhook_configs ORDER BY name ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook configs: %w", err)
+	}
+	defer rows.Close()
+
+	configs := make([]WebhookConfig, 0)
+	for rows.Next() {
+		var w WebhookConfig
+		var enabled int
+		if err := rows.Scan(&w.ID, &w.Name, &w.URL, &w.AuthScheme, &w.AuthToken, &w.SigningSecret,
+			&w.EventFilter, &w.MinStatusCode, &enabled, &w.CreatedAt, &w.UpdatedAt); err != nil {
+			continue
+		}
+		w.Enabled = enabled == 1
+		d.decryptWebhookSecrets(&w)
+		configs = append(configs, w)
+	}
+
+	return configs, nil
+}
+
+// GetEnabledWebhookConfigs returns only the webhooks currently enabled, for
+// the delivery worker to fan a completed request out to.
+func (d *sqliteStore) GetEnabledWebhookConfigs() ([]WebhookConfig, error) {
+	configs, err := d.GetWebhookConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make([]WebhookConfig, 0, len(configs))
+	for _, w := range configs {
+		if w.Enabled {
+			enabled = append(enabled, w)
+		}
+	}
+	return enabled, nil
+}
+
+// GetWebhookConfig returns a single webhook config by ID, with secrets
+// decrypted.
+func (d *sqliteStore) GetWebhookConfig(id string) (*WebhookConfig, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var w WebhookConfig
+	var enabled int
+	err := d.db.QueryRow(
+		`SELECT id, name, url, auth_scheme, auth_token, signing_secret, event_filter, min_status_code, enabled, created_at, updated_at
+		 FROM webhook_configs WHERE id = ?`, id,
+	).Scan(&w.ID, &w.Name, &w.URL, &w.AuthScheme, &w.AuthToken, &w.SigningSecret,
+		&w.EventFilter, &w.MinStatusCode, &enabled, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook config: %w", err)
+	}
+	w.Enabled = enabled == 1
+	d.decryptWebhookSecrets(&w)
+
+	return &w, nil
+}
+
+func (d *sqliteStore) decryptWebhookSecrets(w *WebhookConfig) {
+	if d.secretsMgr == nil {
+		return
+	}
+	if w.AuthToken != "" {
+		if plaintext, err := d.secretsMgr.Decrypt(w.AuthToken); err == nil {
+			w.AuthToken = plaintext
+		}
+	}
+	if w.SigningSecret != "" {
+		if plaintext, err := d.secretsMgr.Decrypt(w.SigningSecret); err == nil {
+			w.SigningSecret = plaintext
+		}
+	}
+}
+
+// UpdateWebhookConfig replaces a webhook config's fields wholesale. Passing
+// an empty authToken or signingSecret clears it, same as an empty value
+// on create.
+func (d *sqliteStore) UpdateWebhookConfig(id, name, url, authScheme, authToken, signingSecret, eventFilter string, minStatusCode int, enabled bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	sealedToken, err := d.sealWebhookSecret(authToken)
+	if err != nil {
+		return err
+	}
+	sealedSecret, err := d.sealWebhookSecret(signingSecret)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	_, err = d.db.Exec(
+		`UPDATE webhook_configs SET
+		   name = ?, url = ?, auth_scheme = ?, auth_token = ?, signing_secret = ?,
+		   event_filter = ?, min_status_code = ?, enabled = ?, updated_at = ?
+		 WHERE id = ?`,
+		name, url, authScheme, sealedToken, sealedSecret, eventFilter, minStatusCode, boolToInt(enabled), now, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook config: %w", err)
+	}
+	return nil
+}
+
+// DeleteWebhookConfig removes a webhook config.
+func (d *sqliteStore) DeleteWebhookConfig(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err := d.db.Exec("DELETE FROM webhook_configs WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+// Package environment resolves "{{var}}" references in request data
+// against the layered set of variable scopes volt-api supports: a
+// request-local override, the collection/folder it belongs to, the active
+// environment, a shared/global environment, and finally the process
+// environment. It has no dependency on the database package, so it can be
+// reused wherever a string needs variables substituted without the caller
+// needing to know how those scopes were populated.
+package environment
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scope names a single layer of variables. An unqualified reference like
+// "{{FOO}}" is resolved by precedence across all scopes below, nearest
+// first; a qualified reference like "{{env.FOO}}" or "{{global.FOO}}"
+// bypasses precedence and reads exactly one scope.
+type Scope string
+
+const (
+	ScopeRequest    Scope = "request"
+	ScopeCollection Scope = "collection"
+	ScopeEnv        Scope = "env"
+	ScopeGlobal     Scope = "global"
+)
+
+// precedence lists scopes from highest to lowest priority for an
+// unqualified reference. Process env is deliberately last and has no
+// explicit-namespace form, since it isn't one of volt-api's own scopes.
+var precedence = []Scope{ScopeRequest, ScopeCollection, ScopeEnv, ScopeGlobal}
+
+// Resolver expands "{{var}}" references against a fixed set of scopes.
+// Any of Request, Collection, Env, or Global may be nil, which is treated
+// as an empty scope.
+type Resolver struct {
+	Request    map[string]string
+	Collection map[string]string
+	Env        map[string]string
+	Global     map[string]string
+}
+
+// NewResolver builds a Resolver over the given scopes.
+func NewResolver(request, collection, env, global map[string]string) *Resolver {
+	return &Resolver{Request: request, Collection: collection, Env: env, Global: global}
+}
+
+// refPattern matches "{{ ... }}", capturing everything between the braces.
+var refPattern = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+
+// Resolve expands every "{{...}}" reference in s. A reference may include a
+// default via "{{VAR|default}}", used when VAR is undefined in every
+// scope; it may also be written as "{{env.VAR}}" or "{{global.VAR}}" to
+// read a single scope directly instead of following precedence. A
+// reference's own value is itself expanded recursively, so "{{A}}" can
+// resolve to a string containing "{{B}}". Resolve returns an error naming
+// the reference chain if expansion would cycle, or if any reference is
+// undefined and has no default.
+func (r *Resolver) Resolve(s string) (string, error) {
+	return r.resolve(s, nil)
+}
+
+func (r *Resolver) resolve(s string, chain []string) (string, error) {
+	var outErr error
+
+	result := refPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if outErr != nil {
+			return match
+		}
+
+		sub := refPattern.FindStringSubmatch(match)
+		ref := sub[1]
+
+		name, def, hasDefault := splitDefault(ref)
+		scope, key := splitScope(name)
+
+		for _, seen := range chain {
+			if seen == name {
+				outErr = fmt.Errorf("variable reference cycle: %s -> %s", strings.Join(chain, " -> "), name)
+				return match
+			}
+		}
+
+		value, ok := r.lookup(scope, key)
+		if !ok {
+			if hasDefault {
+				value = def
+			} else {
+				outErr = fmt.Errorf("undefined variable %q", name)
+				return match
+			}
+		}
+
+		expanded, err := r.resolve(value, append(append([]string{}, chain...), name))
+		if err != nil {
+			outErr = err
+			return match
+		}
+		return expanded
+	})
+
+	if outErr != nil {
+		return "", outErr
+	}
+	return result, nil
+}
+
+// maxResolveDepth bounds ResolveLenient's recursion when a variable's own
+// value contains another reference, as a backstop alongside its
+// chain-based cycle detection: a chain of distinct names that happens to
+// be longer than any real environment would need still terminates cleanly
+// instead of recursing until the stack gives out.
+const maxResolveDepth = 8
+
+// escapePattern matches a backslash-escaped reference like "\{{foo}}",
+// capturing the reference (without the backslash) so ResolveLenient can
+// restore it as literal text instead of expanding it.
+var escapePattern = regexp.MustCompile(`\\(\{\{[^{}]*\}\})`)
+
+// dynamicBuiltins are the "{{$name}}" references ResolveLenient expands to
+// a freshly computed value on every call, independent of any scope —
+// the same small set of dynamic variables Postman offers for generating
+// timestamps, UUIDs, and random numbers inline in a request.
+var dynamicBuiltins = map[string]func() string{
+	"$timestamp":    func() string { return fmt.Sprintf("%d", time.Now().Unix()) },
+	"$isoTimestamp": func() string { return time.Now().UTC().Format(time.RFC3339) },
+	"$randomUUID":   func() string { return uuid.New().String() },
+	"$randomInt":    func() string { return fmt.Sprintf("%d", rand.Intn(1000)) },
+}
+
+// ResolveLenient expands "{{...}}" references the same way Resolve does —
+// including defaults, explicit scopes, and nested references — and also
+// the "{{$timestamp}}"-style dynamic built-ins, which Resolve does not
+// expand. Unlike Resolve, it never fails on an
+// undefined variable. Instead it leaves the reference untouched in the
+// output and reports its name in unresolved (sorted, de-duplicated), so a
+// caller like SendRequest can warn about it without aborting the request.
+// A literal "{{" is written as "\{{" to opt out of expansion entirely. A
+// reference cycle (direct or via maxResolveDepth levels of nesting) still
+// returns an error, since there's no sensible literal output for it.
+func (r *Resolver) ResolveLenient(s string) (result string, unresolved []string, err error) {
+	masked, literals := maskEscapes(s)
+
+	seen := make(map[string]bool)
+	out, err := r.resolveLenient(masked, nil, 0, seen)
+	if err != nil {
+		return "", nil, err
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return unmaskEscapes(out, literals), names, nil
+}
+
+func (r *Resolver) resolveLenient(s string, chain []string, depth int, unresolved map[string]bool) (string, error) {
+	if depth > maxResolveDepth {
+		return "", fmt.Errorf("variable expansion exceeded max depth (%d): %s", maxResolveDepth, strings.Join(chain, " -> "))
+	}
+
+	var outErr error
+
+	result := refPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if outErr != nil {
+			return match
+		}
+
+		sub := refPattern.FindStringSubmatch(match)
+		ref := sub[1]
+
+		if builtin, ok := dynamicBuiltins[ref]; ok {
+			return builtin()
+		}
+
+		name, def, hasDefault := splitDefault(ref)
+		scope, key := splitScope(name)
+
+		for _, seenName := range chain {
+			if seenName == name {
+				outErr = fmt.Errorf("variable reference cycle: %s -> %s", strings.Join(chain, " -> "), name)
+				return match
+			}
+		}
+
+		value, ok := r.lookup(scope, key)
+		if !ok {
+			if hasDefault {
+				value = def
+			} else {
+				unresolved[name] = true
+				return match
+			}
+		}
+
+		expanded, err := r.resolveLenient(value, append(append([]string{}, chain...), name), depth+1, unresolved)
+		if err != nil {
+			outErr = err
+			return match
+		}
+		return expanded
+	})
+
+	if outErr != nil {
+		return "", outErr
+	}
+	return result, nil
+}
+
+// maskEscapes replaces every "\{{...}}" in s with a placeholder token the
+// reference regex won't match, so ResolveLenient passes over it untouched;
+// unmaskEscapes restores the literal "{{...}}" text (without the
+// backslash) once expansion is done.
+func maskEscapes(s string) (string, []string) {
+	var literals []string
+	masked := escapePattern.ReplaceAllStringFunc(s, func(match string) string {
+		literal := match[1:] // drop the leading backslash
+		token := fmt.Sprintf("\x00ESCAPED%d\x00", len(literals))
+		literals = append(literals, literal)
+		return token
+	})
+	return masked, literals
+}
+
+func unmaskEscapes(s string, literals []string) string {
+	for i, literal := range literals {
+		s = strings.ReplaceAll(s, fmt.Sprintf("\x00ESCAPED%d\x00", i), literal)
+	}
+	return s
+}
+
+// lookup resolves key within scope. An empty scope follows precedence
+// across Request, Collection, Env, Global, then os.Getenv.
+func (r *Resolver) lookup(scope Scope, key string) (string, bool) {
+	if scope != "" {
+		v, ok := r.scopeMap(scope)[key]
+		return v, ok
+	}
+
+	for _, s := range precedence {
+		if v, ok := r.scopeMap(s)[key]; ok {
+			return v, true
+		}
+	}
+	return os.LookupEnv(key)
+}
+
+func (r *Resolver) scopeMap(scope Scope) map[string]string {
+	switch scope {
+	case ScopeRequest:
+		return r.Request
+	case ScopeCollection:
+		return r.Collection
+	case ScopeEnv:
+		return r.Env
+	case ScopeGlobal:
+		return r.Global
+	default:
+		return nil
+	}
+}
+
+// splitDefault splits "VAR|default" into ("VAR", "default", true), or
+// returns (ref, "", false) if ref has no "|".
+func splitDefault(ref string) (name, def string, hasDefault bool) {
+	if i := strings.IndexByte(ref, '|'); i >= 0 {
+		return ref[:i], ref[i+1:], true
+	}
+	return ref, "", false
+}
+
+// splitScope splits "env.FOO" or "global.FOO" into their explicit scope and
+// key; any other reference (including one with unrecognized "x.y" syntax)
+// is treated as an unqualified key in its own right.
+func splitScope(name string) (scope Scope, key string) {
+	if strings.HasPrefix(name, "env.") {
+		return ScopeEnv, strings.TrimPrefix(name, "env.")
+	}
+	if strings.HasPrefix(name, "global.") {
+		return ScopeGlobal, strings.TrimPrefix(name, "global.")
+	}
+	return "", name
+}
@@ -0,0 +1,163 @@
+package environment
+
+import "testing"
+
+func TestResolvePrecedence(t *testing.T) {
+	r := NewResolver(
+		map[string]string{"HOST": "request-host"},
+		map[string]string{"HOST": "collection-host", "PORT": "8080"},
+		map[string]string{"HOST": "env-host", "PORT": "9090"},
+		map[string]string{"HOST": "global-host"},
+	)
+
+	got, err := r.Resolve("{{HOST}}:{{PORT}}")
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if want := "request-host:8080"; got != want {
+		t.Errorf("Resolve() = %q, want %q", got, want)
+	}
+}
+
+func TestResolveExplicitScope(t *testing.T) {
+	r := NewResolver(
+		map[string]string{"HOST": "request-host"},
+		nil,
+		map[string]string{"HOST": "env-host"},
+		map[string]string{"HOST": "global-host"},
+	)
+
+	got, err := r.Resolve("{{env.HOST}} {{global.HOST}}")
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if want := "env-host global-host"; got != want {
+		t.Errorf("Resolve() = %q, want %q", got, want)
+	}
+}
+
+func TestResolveDefault(t *testing.T) {
+	r := NewResolver(nil, nil, nil, nil)
+
+	got, err := r.Resolve("{{MISSING|fallback}}")
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if want := "fallback"; got != want {
+		t.Errorf("Resolve() = %q, want %q", got, want)
+	}
+}
+
+func TestResolveMissingVariableErrors(t *testing.T) {
+	r := NewResolver(nil, nil, nil, nil)
+
+	if _, err := r.Resolve("{{MISSING}}"); err == nil {
+		t.Error("Resolve() with an undefined variable and no default should return an error")
+	}
+}
+
+func TestResolveNestedReferences(t *testing.T) {
+	r := NewResolver(
+		nil, nil,
+		map[string]string{
+			"BASE_URL": "https://{{HOST}}/api",
+			"HOST":     "example.com",
+		},
+		nil,
+	)
+
+	got, err := r.Resolve("{{BASE_URL}}/v1")
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if want := "https://example.com/api/v1"; got != want {
+		t.Errorf("Resolve() = %q, want %q", got, want)
+	}
+}
+
+func TestResolveCycleDetection(t *testing.T) {
+	r := NewResolver(
+		nil, nil,
+		map[string]string{
+			"A": "{{B}}",
+			"B": "{{A}}",
+		},
+		nil,
+	)
+
+	if _, err := r.Resolve("{{A}}"); err == nil {
+		t.Error("Resolve() with a cyclic reference should return an error")
+	}
+}
+
+func TestResolveLenientLeavesUnknownVariablesLiteral(t *testing.T) {
+	r := NewResolver(nil, nil, map[string]string{"HOST": "example.com"}, nil)
+
+	got, unresolved, err := r.ResolveLenient("https://{{HOST}}/{{missing}}")
+	if err != nil {
+		t.Fatalf("ResolveLenient returned error: %v", err)
+	}
+	if want := "https://example.com/{{missing}}"; got != want {
+		t.Errorf("ResolveLenient() = %q, want %q", got, want)
+	}
+	if want := []string{"missing"}; !equalStrings(unresolved, want) {
+		t.Errorf("unresolved = %v, want %v", unresolved, want)
+	}
+}
+
+func TestResolveLenientEscape(t *testing.T) {
+	r := NewResolver(nil, nil, map[string]string{"HOST": "example.com"}, nil)
+
+	got, unresolved, err := r.ResolveLenient(`\{{HOST}} is literal, {{HOST}} is not`)
+	if err != nil {
+		t.Fatalf("ResolveLenient returned error: %v", err)
+	}
+	if want := "{{HOST}} is literal, example.com is not"; got != want {
+		t.Errorf("ResolveLenient() = %q, want %q", got, want)
+	}
+	if len(unresolved) != 0 {
+		t.Errorf("unresolved = %v, want none", unresolved)
+	}
+}
+
+func TestResolveLenientDynamicBuiltins(t *testing.T) {
+	r := NewResolver(nil, nil, nil, nil)
+
+	got, unresolved, err := r.ResolveLenient("{{$randomUUID}}")
+	if err != nil {
+		t.Fatalf("ResolveLenient returned error: %v", err)
+	}
+	if len(got) != 36 {
+		t.Errorf("ResolveLenient(%q) = %q, want a UUID", "{{$randomUUID}}", got)
+	}
+	if len(unresolved) != 0 {
+		t.Errorf("unresolved = %v, want none", unresolved)
+	}
+}
+
+func TestResolveLenientCycleStillErrors(t *testing.T) {
+	r := NewResolver(
+		nil, nil,
+		map[string]string{
+			"A": "{{B}}",
+			"B": "{{A}}",
+		},
+		nil,
+	)
+
+	if _, _, err := r.ResolveLenient("{{A}}"); err == nil {
+		t.Error("ResolveLenient() with a cyclic reference should return an error")
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
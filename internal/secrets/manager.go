@@ -0,0 +1,195 @@
+// Package secrets seals environment variable values at rest with an
+// AES-256-GCM data key held in the OS keyring (Keychain on macOS,
+// Credential Manager/DPAPI on Windows, Secret Service on Linux), falling
+// back to a passphrase-derived key when no keyring backend is available.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	keyringService = "volt-api"
+	keyringAccount = "secrets-data-key"
+
+	// encPrefix marks a value as sealed by Manager.Encrypt. Values without
+	// it are treated as plaintext, so rows written before encryption
+	// support existed remain readable.
+	encPrefix = "enc:v1:"
+
+	keySize          = 32
+	pbkdf2Iterations = 100_000
+)
+
+// PassphraseProvider is consulted when the OS keyring has no backend
+// available, to derive a fallback data key from a user-supplied passphrase.
+type PassphraseProvider func() (string, error)
+
+// Manager encrypts and decrypts values under a single data key.
+type Manager struct {
+	mu  sync.RWMutex
+	key []byte
+}
+
+// NewManager loads this install's data key from the OS keyring, generating
+// and storing one on first run. If the keyring has no backend, it falls
+// back to a key derived from passphrase(); passphrase may be nil, in which
+// case a missing keyring is a hard error.
+func NewManager(passphrase PassphraseProvider) (*Manager, error) {
+	key, keyringErr := loadOrCreateKeyringKey()
+	if keyringErr != nil {
+		if passphrase == nil {
+			return nil, fmt.Errorf("keyring unavailable and no passphrase fallback configured: %w", keyringErr)
+		}
+		phrase, err := passphrase()
+		if err != nil {
+			return nil, fmt.Errorf("keyring unavailable (%v) and passphrase prompt failed: %w", keyringErr, err)
+		}
+		key = deriveKeyFromPassphrase(phrase)
+	}
+
+	return &Manager{key: key}, nil
+}
+
+func loadOrCreateKeyringKey() ([]byte, error) {
+	if encoded, err := keyring.Get(keyringService, keyringAccount); err == nil {
+		if key, decodeErr := base64.StdEncoding.DecodeString(encoded); decodeErr == nil && len(key) == keySize {
+			return key, nil
+		}
+	}
+
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	if err := keyring.Set(keyringService, keyringAccount, base64.StdEncoding.EncodeToString(key)); err != nil {
+		return nil, fmt.Errorf("failed to store data key in keyring: %w", err)
+	}
+	return key, nil
+}
+
+// deriveKeyFromPassphrase stretches a user passphrase into a 32-byte key.
+// The salt is fixed since it only replaces the keyring as a key source, not
+// as a source of randomness.
+func deriveKeyFromPassphrase(passphrase string) []byte {
+	salt := []byte("volt-api-secrets-fallback-salt")
+	return pbkdf2.Key([]byte(passphrase), salt, pbkdf2Iterations, keySize, sha256.New)
+}
+
+// Encrypt seals plaintext under the manager's current data key, returning
+// "enc:v1:" followed by base64(nonce || ciphertext).
+func (m *Manager) Encrypt(plaintext string) (string, error) {
+	m.mu.RLock()
+	key := m.key
+	m.mu.RUnlock()
+	return encryptWithKey(key, plaintext)
+}
+
+// Decrypt opens a value previously returned by Encrypt. A value without the
+// enc:v1: prefix is returned unchanged.
+func (m *Manager) Decrypt(value string) (string, error) {
+	m.mu.RLock()
+	key := m.key
+	m.mu.RUnlock()
+	return decryptWithKey(key, value)
+}
+
+// IsEncrypted reports whether value carries the enc:v1: prefix.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encPrefix)
+}
+
+// RotateKey generates a new data key, stores it in the keyring, and swaps it
+// in as the manager's current key. It returns the key that was current
+// before rotation, so the caller can re-encrypt rows sealed under it via
+// ReencryptWithOldKey.
+func (m *Manager) RotateKey() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	newKey := make([]byte, keySize)
+	if _, err := rand.Read(newKey); err != nil {
+		return nil, fmt.Errorf("failed to generate new data key: %w", err)
+	}
+	if err := keyring.Set(keyringService, keyringAccount, base64.StdEncoding.EncodeToString(newKey)); err != nil {
+		return nil, fmt.Errorf("failed to store new data key in keyring: %w", err)
+	}
+
+	oldKey := m.key
+	m.key = newKey
+	return oldKey, nil
+}
+
+// ReencryptWithOldKey decrypts value with oldKey and re-encrypts it under
+// the manager's current key, for migrating rows after RotateKey.
+func (m *Manager) ReencryptWithOldKey(oldKey []byte, value string) (string, error) {
+	plaintext, err := decryptWithKey(oldKey, value)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.RLock()
+	key := m.key
+	m.mu.RUnlock()
+	return encryptWithKey(key, plaintext)
+}
+
+func encryptWithKey(key []byte, plaintext string) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func decryptWithKey(key []byte, value string) (string, error) {
+	if !strings.HasPrefix(value, encPrefix) {
+		return value, nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encPrefix))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode encrypted value: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("encrypted value is too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// s3Backend stores objects in a single S3(-compatible) bucket, using S3's
+// conditional-write support (If-Match / PreconditionFailed) for the same
+// ETag-based optimistic concurrency the other backends provide.
+type s3Backend struct {
+	client *s3.Client
+	bucket string
+}
+
+// newS3Backend builds an s3Backend from config. Recognized keys:
+// "bucket" (required), "region", "endpoint" (for S3-compatible services
+// like MinIO or R2), and "accessKeyID"/"secretAccessKey" (falls back to
+// the default AWS credential chain when unset).
+func newS3Backend(cfg map[string]string) (Backend, error) {
+	bucket := cfg["bucket"]
+	if bucket == "" {
+		return nil, fmt.Errorf("s3 storage backend requires a \"bucket\" config value")
+	}
+
+	ctx := context.Background()
+	var opts []func(*config.LoadOptions) error
+	if region := cfg["region"]; region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+	if cfg["accessKeyID"] != "" && cfg["secretAccessKey"] != "" {
+		opts = append(opts, config.WithCredentialsProvider(aws.CredentialsProviderFunc(
+			func(ctx context.Context) (aws.Credentials, error) {
+				return aws.Credentials{
+					AccessKeyID:     cfg["accessKeyID"],
+					SecretAccessKey: cfg["secretAccessKey"],
+				}, nil
+			},
+		)))
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if endpoint := cfg["endpoint"]; endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+
+	return &s3Backend{client: client, bucket: bucket}, nil
+}
+
+func (b *s3Backend) Get(key string) (Object, error) {
+	ctx := context.Background()
+
+	out, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	})
+	var nsk *types.NoSuchKey
+	if errors.As(err, &nsk) {
+		return Object{}, ErrNotFound
+	}
+	if err != nil {
+		return Object{}, err
+	}
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return Object{}, err
+	}
+
+	var updatedAt int64
+	if out.LastModified != nil {
+		updatedAt = out.LastModified.Unix()
+	}
+
+	return Object{
+		Key:       key,
+		Data:      data,
+		ETag:      unquoteETag(aws.ToString(out.ETag)),
+		UpdatedAt: updatedAt,
+	}, nil
+}
+
+func (b *s3Backend) Put(key string, data []byte, ifMatch string) (string, error) {
+	ctx := context.Background()
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	}
+	if ifMatch != "" {
+		input.IfMatch = aws.String(ifMatch)
+	}
+
+	out, err := b.client.PutObject(ctx, input)
+	if isPreconditionFailed(err) {
+		return "", ErrPreconditionFailed
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return unquoteETag(aws.ToString(out.ETag)), nil
+}
+
+func (b *s3Backend) List(prefix string) ([]Object, error) {
+	ctx := context.Background()
+
+	var objects []Object
+	paginator := s3.NewListObjectsV2Paginator(b.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Contents {
+			var updatedAt int64
+			if item.LastModified != nil {
+				updatedAt = item.LastModified.Unix()
+			}
+			objects = append(objects, Object{
+				Key:       aws.ToString(item.Key),
+				ETag:      unquoteETag(aws.ToString(item.ETag)),
+				UpdatedAt: updatedAt,
+			})
+		}
+	}
+
+	return objects, nil
+}
+
+// isPreconditionFailed reports whether err is the S3 error returned when a
+// PutObject's If-Match header doesn't match the object's current ETag.
+func isPreconditionFailed(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr interface{ ErrorCode() string }
+	if errors.As(err, &apiErr) {
+		code := apiErr.ErrorCode()
+		return code == "PreconditionFailed" || code == "412"
+	}
+
+	var respErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &respErr) {
+		return respErr.HTTPStatusCode() == 412
+	}
+
+	return false
+}
+
+// unquoteETag strips the surrounding quotes S3 wraps ETags in, so it's
+// directly comparable with the hex ETags the local and Swift backends
+// produce.
+func unquoteETag(etag string) string {
+	if len(etag) >= 2 && etag[0] == '"' && etag[len(etag)-1] == '"' {
+		return etag[1 : len(etag)-1]
+	}
+	return etag
+}
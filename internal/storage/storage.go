@@ -0,0 +1,64 @@
+// Package storage provides a pluggable object-storage backend so the app
+// package can sync collections, environments, and history to a remote
+// bucket instead of (or in addition to) the local database. It follows the
+// same scheme-switch shape as database.Open: callers pick a backend by
+// kind and a small config map, not by importing a concrete type.
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is returned by Get when no object exists at the given key.
+var ErrNotFound = errors.New("storage: object not found")
+
+// ErrPreconditionFailed is returned by Put when ifMatch is non-empty and
+// does not match the object's current ETag, mirroring the conditional-write
+// semantics of S3's If-Match and Swift's If-Match headers. Callers use this
+// to detect a concurrent write and re-run their merge.
+var ErrPreconditionFailed = errors.New("storage: precondition failed")
+
+// Object is a blob read back from a Backend, along with the metadata needed
+// to detect concurrent modification.
+type Object struct {
+	Key       string
+	Data      []byte
+	ETag      string
+	UpdatedAt int64 // unix seconds
+}
+
+// Backend is the minimal surface SyncNow needs from a remote object store:
+// content-addressed reads, conditional writes, and prefix listing.
+type Backend interface {
+	// Get fetches the object at key, or ErrNotFound if it doesn't exist.
+	Get(key string) (Object, error)
+
+	// Put writes data to key and returns the resulting ETag. If ifMatch is
+	// non-empty, the write only succeeds if the object's current ETag
+	// equals ifMatch (or the object doesn't exist and ifMatch is the
+	// empty-object sentinel ""); otherwise it fails with
+	// ErrPreconditionFailed so the caller can re-fetch and merge.
+	Put(key string, data []byte, ifMatch string) (etag string, err error)
+
+	// List returns every object whose key starts with prefix. Data is not
+	// populated; callers that need contents call Get per key.
+	List(prefix string) ([]Object, error)
+}
+
+// NewBackend builds a Backend of the given kind from config. kind must be
+// one of "local", "s3", or "swift" — unlike database.Open, it does not
+// treat "" as a default, since the app package uses an empty kind to mean
+// "sync is disabled" and should never reach this far.
+func NewBackend(kind string, config map[string]string) (Backend, error) {
+	switch kind {
+	case "local":
+		return newLocalBackend(config)
+	case "s3":
+		return newS3Backend(config)
+	case "swift":
+		return newSwiftBackend(config)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend %q", kind)
+	}
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ncw/swift"
+)
+
+// swiftBackend stores objects in a single OpenStack Swift container, using
+// the object's MD5 hash (Swift's native ETag equivalent) for the same
+// optimistic-concurrency role the other backends fill with a content hash.
+type swiftBackend struct {
+	conn      *swift.Connection
+	container string
+}
+
+// newSwiftBackend builds a swiftBackend from config. Recognized keys:
+// "container" (required), "authUrl", "username", "apiKey"/"password",
+// "tenant" (all required for authentication).
+func newSwiftBackend(cfg map[string]string) (Backend, error) {
+	container := cfg["container"]
+	if container == "" {
+		return nil, fmt.Errorf("swift storage backend requires a \"container\" config value")
+	}
+
+	conn := &swift.Connection{
+		AuthUrl:  cfg["authUrl"],
+		UserName: cfg["username"],
+		ApiKey:   cfg["apiKey"],
+		Tenant:   cfg["tenant"],
+	}
+	if conn.ApiKey == "" {
+		conn.ApiKey = cfg["password"]
+	}
+
+	if err := conn.Authenticate(); err != nil {
+		return nil, fmt.Errorf("failed to authenticate with swift: %w", err)
+	}
+
+	if err := conn.ContainerCreate(container, nil); err != nil {
+		return nil, fmt.Errorf("failed to ensure swift container: %w", err)
+	}
+
+	return &swiftBackend{conn: conn, container: container}, nil
+}
+
+func (b *swiftBackend) Get(key string) (Object, error) {
+	var buf bytes.Buffer
+	_, err := b.conn.ObjectGet(b.container, key, &buf, true, nil)
+	if err == swift.ObjectNotFound {
+		return Object{}, ErrNotFound
+	}
+	if err != nil {
+		return Object{}, err
+	}
+
+	info, _, err := b.conn.Object(b.container, key)
+	if err != nil {
+		return Object{}, err
+	}
+
+	return Object{
+		Key:       key,
+		Data:      buf.Bytes(),
+		ETag:      info.Hash,
+		UpdatedAt: info.LastModified.Unix(),
+	}, nil
+}
+
+func (b *swiftBackend) Put(key string, data []byte, ifMatch string) (string, error) {
+	if ifMatch != "" {
+		current, _, err := b.conn.Object(b.container, key)
+		switch {
+		case err == swift.ObjectNotFound:
+			return "", ErrPreconditionFailed
+		case err != nil:
+			return "", err
+		case current.Hash != ifMatch:
+			return "", ErrPreconditionFailed
+		}
+	}
+
+	headers, err := b.conn.ObjectPut(b.container, key, bytes.NewReader(data), true, "", "", nil)
+	if err != nil {
+		return "", err
+	}
+
+	if etag := headers["Etag"]; etag != "" {
+		return etag, nil
+	}
+
+	info, _, err := b.conn.Object(b.container, key)
+	if err != nil {
+		return "", err
+	}
+	return info.Hash, nil
+}
+
+func (b *swiftBackend) List(prefix string) ([]Object, error) {
+	names, err := b.conn.ObjectNamesAll(b.container, &swift.ObjectsOpts{Prefix: prefix})
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]Object, 0, len(names))
+	for _, name := range names {
+		info, _, err := b.conn.Object(b.container, name)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, Object{
+			Key:       name,
+			ETag:      info.Hash,
+			UpdatedAt: info.LastModified.Unix(),
+		})
+	}
+
+	return objects, nil
+}
@@ -2,10 +2,14 @@ package main
 
 import (
 	"embed"
+	"flag"
+	"fmt"
 	"net/http"
+	"os"
 	"runtime"
 
 	"volt-api/internal/app"
+	"volt-api/internal/database"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -17,6 +21,17 @@ import (
 var assets embed.FS
 
 func main() {
+	migrateDown := flag.Int("migrate-down", -1, "revert the local database to this schema version and exit")
+	flag.Parse()
+
+	if *migrateDown >= 0 {
+		if err := runMigrateDown(*migrateDown); err != nil {
+			fmt.Println("Error:", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create an instance of the app structure
 	application := app.New()
 
@@ -68,3 +83,14 @@ func main() {
 		println("Error:", err.Error())
 	}
 }
+
+// runMigrateDown handles the -migrate-down CLI flag: it reverts the local
+// database to schemaVersion without starting the Wails application.
+func runMigrateDown(schemaVersion int) error {
+	fmt.Printf("Reverting database to schema version %d...\n", schemaVersion)
+	if err := database.MigrateDown(schemaVersion); err != nil {
+		return err
+	}
+	fmt.Println("Done.")
+	return nil
+}